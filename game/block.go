@@ -4,14 +4,35 @@ package game
 // the coordinates of its pieces (Points) and all possible rotations, encoded in
 // RotationDeltas. These are the deltas that have to be added to the points to
 // get the next rotation.
+// Kicks are the offsets that are tried in order when a rotation is blocked.
+// Kicks[r] is used when rotating right from rotation r, rotating left from
+// rotation r uses the negated offsets of Kicks[r-1]. Without Kicks, blocked
+// rotations are not possible.
+// Shape tells which of the standard blocks this is, if any. It is used for
+// detecting special moves like T-spins.
 type Block struct {
+	Shape          Shape
 	Points         []Point
 	RotationDeltas [][]Point
+	Kicks          [][]Point
 	rotation       int
 }
 
 type Point struct{ X, Y int }
 
+type Shape int
+
+const (
+	OtherShape Shape = iota
+	OShape
+	IShape
+	JShape
+	LShape
+	SShape
+	TShape
+	ZShape
+)
+
 // Size calculates the current maximum x and y spread. It can change depending
 // on the current rotation of the Block.
 func (b *Block) Size() (w, h int) {
@@ -49,6 +70,28 @@ func (b *Block) RotateRight() {
 	}
 }
 
+func (b *Block) rightKicks() []Point {
+	if len(b.Kicks) == 0 {
+		return nil
+	}
+	return b.Kicks[b.rotation]
+}
+
+func (b *Block) leftKicks() []Point {
+	if len(b.Kicks) == 0 {
+		return nil
+	}
+	return negated(b.Kicks[(b.rotation+len(b.Kicks)-1)%len(b.Kicks)])
+}
+
+func negated(points []Point) []Point {
+	n := make([]Point, len(points))
+	for i, p := range points {
+		n[i] = Point{-p.X, -p.Y}
+	}
+	return n
+}
+
 func (b *Block) increaseRotation() {
 	b.rotation = (b.rotation + 1) % len(b.RotationDeltas)
 }
@@ -79,18 +122,28 @@ func (b *Block) MoveBy(dx, dy int) {
 // Copy creates an exact copy of the Block but with newly created arrays so that
 // changing the copy does not change the original.
 func (b *Block) Copy() Block {
-	c := Block{}
+	c := Block{Shape: b.Shape}
 
 	c.Points = make([]Point, len(b.Points))
 	copy(c.Points, b.Points)
 
-	c.RotationDeltas = make([][]Point, len(b.RotationDeltas))
-	for i := range c.RotationDeltas {
-		c.RotationDeltas[i] = make([]Point, len(b.RotationDeltas[i]))
-		copy(c.RotationDeltas[i], b.RotationDeltas[i])
-	}
+	c.RotationDeltas = copyPoints(b.RotationDeltas)
+
+	c.Kicks = copyPoints(b.Kicks)
 
 	c.rotation = b.rotation
 
 	return c
 }
+
+func copyPoints(points [][]Point) [][]Point {
+	if points == nil {
+		return nil
+	}
+	c := make([][]Point, len(points))
+	for i := range c {
+		c[i] = make([]Point, len(points[i]))
+		copy(c[i], points[i])
+	}
+	return c
+}
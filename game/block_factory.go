@@ -1,11 +1,75 @@
 package game
 
-type blockFactory struct{}
+type blockFactory struct {
+	kicks KickSystem
+}
 
+// NewBlockFactory creates blocks without kicks, they can not rotate when the
+// rotation is blocked.
 func NewBlockFactory() blockFactory { return blockFactory{} }
 
+// NewBlockFactoryWithKicks creates blocks that try the kicks of the given
+// KickSystem when a rotation is blocked.
+func NewBlockFactoryWithKicks(kicks KickSystem) blockFactory {
+	return blockFactory{kicks: kicks}
+}
+
+// KickSystem decides which offsets a block tries if its rotation is blocked.
+type KickSystem int
+
+const (
+	NoKicks KickSystem = iota
+	// SimpleKicks try to move the block one field right, then left.
+	SimpleKicks
+	// SuperRotationSystem uses the kick tables of the Tetris Guideline. Blocks
+	// with only two rotations use the kicks for the first rotation, and the
+	// negated ones for rotating back.
+	SuperRotationSystem
+)
+
+var simpleKicks = []Point{{0, 0}, {1, 0}, {-1, 0}}
+
+var jlstzKicks = [][]Point{
+	{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+}
+
+// spawnedDown shifts the kick table by two states for the blocks that are
+// created pointing down. The Guideline lets them start pointing up, so this
+// package's rotation 0 is their rotation state 2.
+func spawnedDown(kicks [][]Point) [][]Point {
+	return append(append([][]Point{}, kicks[2:]...), kicks[:2]...)
+}
+
+var iKicks = [][]Point{
+	{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+}
+
+func (f blockFactory) withKicks(srs [][]Point, b Block) Block {
+	rotations := len(b.RotationDeltas)
+	switch f.kicks {
+	case SimpleKicks:
+		b.Kicks = make([][]Point, rotations)
+		for i := range b.Kicks {
+			b.Kicks[i] = simpleKicks
+		}
+	case SuperRotationSystem:
+		if rotations == len(srs) {
+			b.Kicks = srs
+		} else {
+			b.Kicks = [][]Point{srs[0], negated(srs[0])}
+		}
+	}
+	return b.Copy()
+}
+
 func (blockFactory) CreateO() Block {
-	return Block{Points: []Point{
+	return Block{Shape: OShape, Points: []Point{
 		{0, 0},
 		{0, 1},
 		{1, 0},
@@ -13,8 +77,8 @@ func (blockFactory) CreateO() Block {
 	}}
 }
 
-func (blockFactory) CreateI() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateI() Block {
+	return f.withKicks(iKicks, Block{Shape: IShape, Points: []Point{
 		{0, 0},
 		{1, 0},
 		{2, 0},
@@ -31,11 +95,11 @@ func (blockFactory) CreateI() Block {
 			{1, -1},
 			{2, -2},
 		},
-	}}
+	}})
 }
 
-func (blockFactory) CreateL() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateL() Block {
+	return f.withKicks(spawnedDown(jlstzKicks), Block{Shape: LShape, Points: []Point{
 		{2, 1},
 		{1, 1},
 		{0, 1},
@@ -62,11 +126,11 @@ func (blockFactory) CreateL() Block {
 			{-1, 1},
 			{-2, 0},
 		},
-	}}
+	}})
 }
 
-func (blockFactory) CreateJ() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateJ() Block {
+	return f.withKicks(spawnedDown(jlstzKicks), Block{Shape: JShape, Points: []Point{
 		{0, 1},
 		{1, 1},
 		{2, 1},
@@ -93,11 +157,11 @@ func (blockFactory) CreateJ() Block {
 			{1, -1},
 			{0, -2},
 		},
-	}}
+	}})
 }
 
-func (blockFactory) CreateT() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateT() Block {
+	return f.withKicks(spawnedDown(jlstzKicks), Block{Shape: TShape, Points: []Point{
 		{1, 1},
 		{0, 1},
 		{1, 0},
@@ -124,11 +188,11 @@ func (blockFactory) CreateT() Block {
 			{-1, -1},
 			{1, -1},
 		},
-	}}
+	}})
 }
 
-func (blockFactory) CreateS() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateS() Block {
+	return f.withKicks(jlstzKicks, Block{Shape: SShape, Points: []Point{
 		{0, 0},
 		{1, 0},
 		{1, 1},
@@ -145,11 +209,11 @@ func (blockFactory) CreateS() Block {
 			{1, 0},
 			{2, -1},
 		},
-	}}
+	}})
 }
 
-func (blockFactory) CreateZ() Block {
-	return Block{Points: []Point{
+func (f blockFactory) CreateZ() Block {
+	return f.withKicks(jlstzKicks, Block{Shape: ZShape, Points: []Point{
 		{0, 1},
 		{1, 1},
 		{1, 0},
@@ -166,5 +230,5 @@ func (blockFactory) CreateZ() Block {
 			{1, -1},
 			{2, 0},
 		},
-	}}
+	}})
 }
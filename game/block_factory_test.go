@@ -143,6 +143,82 @@ func TestZHasTwoRotations(t *testing.T) {
 	checkBlockEquals(t, Z, "2x left", z)
 }
 
+func TestBlocksKnowTheirShape(t *testing.T) {
+	f := NewBlockFactory()
+	blocks := []Block{f.CreateO(), f.CreateI(), f.CreateJ(), f.CreateL(),
+		f.CreateS(), f.CreateT(), f.CreateZ()}
+	shapes := []Shape{OShape, IShape, JShape, LShape, SShape, TShape, ZShape}
+	for i, b := range blocks {
+		if b.Shape != shapes[i] {
+			t.Error("expected shape", shapes[i], "but was", b.Shape)
+		}
+		if c := b.Copy(); c.Shape != b.Shape {
+			t.Error("shape not copied")
+		}
+	}
+}
+
+func TestDefaultFactoryCreatesBlocksWithoutKicks(t *testing.T) {
+	for _, b := range allBlocks(NewBlockFactory()) {
+		if b.Kicks != nil {
+			t.Error("block has kicks", b)
+		}
+	}
+}
+
+func TestKicksAreGivenForEachRotation(t *testing.T) {
+	for _, kicks := range []KickSystem{SimpleKicks, SuperRotationSystem} {
+		for _, b := range allBlocks(NewBlockFactoryWithKicks(kicks)) {
+			if len(b.Kicks) != len(b.RotationDeltas) {
+				t.Error(kicks, "kick count does not match rotation count", b)
+			}
+		}
+	}
+}
+
+func TestSuperRotationSystemUsesGuidelineKicks(t *testing.T) {
+	f := NewBlockFactoryWithKicks(SuperRotationSystem)
+	checkKicksEqual(t, f.CreateT().Kicks[0],
+		[]Point{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}})
+	checkKicksEqual(t, f.CreateT().Kicks[2],
+		[]Point{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}})
+	checkKicksEqual(t, f.CreateJ().Kicks[1],
+		[]Point{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}})
+	checkKicksEqual(t, f.CreateS().Kicks[0],
+		[]Point{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}})
+	checkKicksEqual(t, f.CreateI().Kicks[0],
+		[]Point{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}})
+	checkKicksEqual(t, f.CreateI().Kicks[1],
+		[]Point{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}})
+}
+
+func TestRandomizersUseTheFactorysKicks(t *testing.T) {
+	next := NewBlockFactoryWithKicks(SimpleKicks).SevenBagRandomizer(0)
+	for i := 0; i < 7; i++ {
+		if b := next(); len(b.Kicks) != len(b.RotationDeltas) {
+			t.Error("block without kicks", b)
+		}
+	}
+}
+
+func allBlocks(f blockFactory) []Block {
+	return []Block{
+		f.CreateO(),
+		f.CreateI(),
+		f.CreateL(),
+		f.CreateJ(),
+		f.CreateT(),
+		f.CreateS(),
+		f.CreateZ(),
+	}
+}
+
+func checkKicksEqual(t *testing.T, actual, expected []Point) {
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Error("expected kicks", expected, "but were", actual)
+	}
+}
+
 func checkBlockEquals(t *testing.T, b Block, msg string, expected []Point) {
 	actual := fmt.Sprint(b.Points)
 	exp := fmt.Sprint(expected)
@@ -140,6 +140,10 @@ func createTestBlock() Block {
 			[]Point{{5, 6}, {7, 8}},
 			[]Point{{9, 10}, {11, 12}},
 		},
+		Kicks: [][]Point{
+			[]Point{{0, 0}, {1, 0}},
+			[]Point{{0, 0}, {-1, 0}},
+		},
 		rotation: 2,
 	}
 }
@@ -152,6 +156,7 @@ func TestChangingBlockCopyDoesNotAffectOriginal(t *testing.T) {
 	copy.rotation = -1
 	copy.RotationDeltas[0][0] = Point{-1, -1}
 	copy.RotationDeltas[1][1] = Point{-1, -1}
+	copy.Kicks[1][0] = Point{-1, -1}
 	actual := fmt.Sprint(original)
 	if expected != actual {
 		t.Error("\n", expected, "expected but original changed to\n", actual)
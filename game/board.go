@@ -1,46 +1,59 @@
 package game
 
 // board encodes the game field, a rectangular area where solid block pieces are
-// stored.
-type board [][]int
+// stored. Rows above the visible height are hidden but otherwise work just like
+// the visible ones.
+type board struct {
+	fields  [][]int
+	visible int
+}
 
 func newBoard(w, h int) board {
-	b := make([][]int, h)
+	return newBoardWithHiddenRows(w, h, 0)
+}
+
+func newBoardWithHiddenRows(w, h, hidden int) board {
+	b := make([][]int, h+hidden)
 	for y := range b {
 		b[y] = make([]int, w)
 		for x := range b[y] {
 			b[y][x] = NoPlayer
 		}
 	}
-	return b
+	return board{fields: b, visible: h}
 }
 
 func (b board) isBlocked(x, y int) bool {
-	return y < len(b) && b[y][x] != NoPlayer
+	return y < len(b.fields) && b.fields[y][x] != NoPlayer
 }
 
+// Size returns the size of the board including the hidden rows.
 func (b board) Size() (w, h int) {
-	if len(b) == 0 {
+	if len(b.fields) == 0 {
 		return 0, 0
 	}
-	return len(b[0]), len(b)
+	return len(b.fields[0]), len(b.fields)
+}
+
+func (b board) VisibleHeight() int {
+	return b.visible
 }
 
 func (b board) At(x, y int) int {
-	return b[y][x]
+	return b.fields[y][x]
 }
 
 func (b board) SetAt(x, y, setTo int) {
-	b[y][x] = setTo
+	b.fields[y][x] = setTo
 }
 
 // Copy creates a new board copying the original arrays so that changing the
 // copy does not change the orignial.
 func (b board) Copy() Board {
-	c := make([][]int, len(b))
+	c := make([][]int, len(b.fields))
 	for i := range c {
-		c[i] = make([]int, len(b[i]))
-		copy(c[i], b[i])
+		c[i] = make([]int, len(b.fields[i]))
+		copy(c[i], b.fields[i])
 	}
-	return board(c)
+	return board{fields: c, visible: b.visible}
 }
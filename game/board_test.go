@@ -63,3 +63,20 @@ func TestChangingBoardCopyDoesNotChangeOriginal(t *testing.T) {
 		t.Error("original changed to", player)
 	}
 }
+
+func TestHiddenRowsAreAboveTheVisibleHeight(t *testing.T) {
+	b := newBoardWithHiddenRows(3, 5, 2)
+	if w, h := b.Size(); w != 3 || h != 7 {
+		t.Error("wrong size", w, h)
+	}
+	if h := b.VisibleHeight(); h != 5 {
+		t.Error("wrong visible height", h)
+	}
+	b.SetAt(1, 6, 4)
+	if player := b.At(1, 6); player != 4 {
+		t.Error("hidden position not set but was", player)
+	}
+	if h := b.Copy().VisibleHeight(); h != 5 {
+		t.Error("copy has wrong visible height", h)
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// CoopScorer lets all players play for one shared score. Lines are scored like
+// for a single team and every line that was completed by several players in the
+// same frame earns an extra bonus for each helping player.
+type CoopScorer struct {
+	score      int
+	jointBonus int
+}
+
+func NewCoopScorer() *CoopScorer {
+	return &CoopScorer{jointBonus: 1}
+}
+
+// SetJointLineBonus sets the points given for each additional player that
+// helped to complete a line. The default is 1.
+func (s *CoopScorer) SetJointLineBonus(points int) {
+	s.jointBonus = points
+}
+
+func (s *CoopScorer) Score() int {
+	return s.score
+}
+
+func (s *CoopScorer) LinesRemoved(linesForPlayer [][]int) {
+	var allLines []int
+	for _, lines := range linesForPlayer {
+		allLines = append(allLines, lines...)
+	}
+	s.score += lineScore(countDistinct(allLines))
+	for i, line := range allLines {
+		if !contains(allLines[:i], line) {
+			helpers := countPlayersWithLine(linesForPlayer, line) - 1
+			s.score += helpers * s.jointBonus
+		}
+	}
+}
+
+func countPlayersWithLine(linesForPlayer [][]int, line int) int {
+	count := 0
+	for _, lines := range linesForPlayer {
+		if contains(lines, line) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *CoopScorer) Reset() {
+	s.score = 0
+}
+
+type coopScorerState struct {
+	Score      int
+	JointBonus int
+}
+
+func (s *CoopScorer) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(coopScorerState{s.score, s.jointBonus})
+	return buf.Bytes(), err
+}
+
+func (s *CoopScorer) UnmarshalBinary(data []byte) error {
+	var state coopScorerState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	s.score = state.Score
+	s.jointBonus = state.JointBonus
+	return nil
+}
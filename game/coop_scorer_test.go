@@ -2,6 +2,92 @@ package game
 
 import "testing"
 
-func TestTODO(t *testing.T) {
+func TestCoopScoreIsInitiallyZero(t *testing.T) {
+	s := NewCoopScorer()
+	if score := s.Score(); score != 0 {
+		t.Error("expected 0 but score was", score)
+	}
+}
+
+func TestLinesOfAllPlayersAddUpToOneScore(t *testing.T) {
+	s := NewCoopScorer()
+	s.LinesRemoved([][]int{
+		{1, 2},
+		{},
+		{3},
+	})
+	if score := s.Score(); score != lineScore(3) {
+		t.Errorf("expected %v but score was %v", lineScore(3), score)
+	}
+}
+
+func TestCoopScoresAddUpWithRemovedLines(t *testing.T) {
+	s := NewCoopScorer()
+	s.LinesRemoved([][]int{{1}})
+	s.LinesRemoved([][]int{{}, {1, 2}})
+	expected := lineScore(1) + lineScore(2)
+	if score := s.Score(); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
+}
+
+func TestJointlyCompletedLinesGiveBonusForEachHelpingPlayer(t *testing.T) {
+	s := NewCoopScorer()
+	s.LinesRemoved([][]int{
+		{1, 2},
+		{1, 3},
+		{1},
+	})
+	expected := lineScore(3) + 2
+	if score := s.Score(); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
+}
+
+func TestJointLineBonusCanBeChanged(t *testing.T) {
+	s := NewCoopScorer()
+	s.SetJointLineBonus(5)
+	s.LinesRemoved([][]int{{1}, {1}})
+	expected := lineScore(1) + 5
+	if score := s.Score(); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
+}
+
+func TestLinesOfOnePlayerInDifferentFramesAreNoJointLines(t *testing.T) {
+	s := NewCoopScorer()
+	s.LinesRemoved([][]int{{1}, {}})
+	s.LinesRemoved([][]int{{}, {1}})
+	expected := lineScore(1) + lineScore(1)
+	if score := s.Score(); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
+}
+
+func TestResettingSetsCoopScoreToZero(t *testing.T) {
+	s := NewCoopScorer()
+	s.LinesRemoved([][]int{{1, 2, 3}, {3}})
+	s.Reset()
+	if score := s.Score(); score != 0 {
+		t.Errorf("expected 0 but score was %v", score)
+	}
+}
 
+func TestCoopScoreCanBeMarshaled(t *testing.T) {
+	s := NewCoopScorer()
+	s.SetJointLineBonus(3)
+	s.LinesRemoved([][]int{{1, 2}})
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewCoopScorer()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	restored.LinesRemoved([][]int{{1}, {1}})
+	expected := lineScore(2) + lineScore(1) + 3
+	if score := restored.Score(); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
 }
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GuidelineScorer scores every player separately, similar to the official
+// guideline games. Removed lines and T-spins are worth more on higher levels,
+// consecutive line clears give combo bonuses and difficult clears (four or
+// more lines or a T-spin with lines) in a row are worth half as much again.
+// Soft dropped fields give 1 point and hard dropped fields 2 points each.
+type GuidelineScorer struct {
+	level      func() int
+	scores     []int
+	clears     []int
+	backToBack []bool
+}
+
+func NewGuidelineScorer() *GuidelineScorer {
+	return &GuidelineScorer{}
+}
+
+// SetLevelFunc sets the function that reports the current level, e.g. a
+// Logic's Level method. Without it, the level is always 1.
+func (s *GuidelineScorer) SetLevelFunc(level func() int) {
+	s.level = level
+}
+
+func (s *GuidelineScorer) currentLevel() int {
+	if s.level == nil {
+		return 1
+	}
+	return s.level()
+}
+
+func (s *GuidelineScorer) ScoreForPlayer(player int) int {
+	if player < len(s.scores) {
+		return s.scores[player]
+	}
+	return 0
+}
+
+// LinesRemoved does nothing, lines are scored in BlocksLocked.
+func (s *GuidelineScorer) LinesRemoved(linesForPlayer [][]int) {}
+
+func (s *GuidelineScorer) BlocksLocked(events []LockEvent) {
+	level := s.currentLevel()
+	for _, e := range events {
+		s.grow(e.Player)
+		lines := len(e.Lines)
+		points := actionScore(lines, e.TSpin) * level
+		if lines > 0 {
+			difficult := lines >= 4 || e.TSpin != NoTSpin
+			if difficult && s.backToBack[e.Player] {
+				points = points * 3 / 2
+			}
+			s.backToBack[e.Player] = difficult
+			points += 50 * s.clears[e.Player] * level
+			s.clears[e.Player]++
+		} else {
+			s.clears[e.Player] = 0
+		}
+		s.scores[e.Player] += points + e.SoftDropDistance + 2*e.HardDropDistance
+	}
+}
+
+func (s *GuidelineScorer) grow(player int) {
+	s.scores = grow(s.scores, player)
+	s.clears = grow(s.clears, player)
+	for len(s.backToBack) <= player {
+		s.backToBack = append(s.backToBack, false)
+	}
+}
+
+var (
+	lineClearScores = []int{0, 100, 300, 500, 800}
+	miniTSpinScores = []int{100, 200, 400}
+	tSpinScores     = []int{400, 800, 1200, 1600}
+)
+
+// actionScore is the score for a locked block on level 1. Clearing more lines
+// than listed in the tables scores like the maximum.
+func actionScore(lines int, tSpin TSpin) int {
+	scores := lineClearScores
+	if tSpin == MiniTSpin {
+		scores = miniTSpinScores
+	} else if tSpin == FullTSpin {
+		scores = tSpinScores
+	}
+	if lines >= len(scores) {
+		lines = len(scores) - 1
+	}
+	return scores[lines]
+}
+
+func (s *GuidelineScorer) PlayerAdded(player int) {}
+
+// PlayerRemoved forgets the player's score. The following players move down by
+// one, keeping their scores.
+func (s *GuidelineScorer) PlayerRemoved(player int) {
+	if player < len(s.scores) {
+		s.scores = withoutInt(s.scores, player)
+		s.clears = withoutInt(s.clears, player)
+		s.backToBack = withoutBool(s.backToBack, player)
+	}
+}
+
+func (s *GuidelineScorer) Reset() {
+	s.scores = nil
+	s.clears = nil
+	s.backToBack = nil
+}
+
+type guidelineScorerState struct {
+	Scores     []int
+	Clears     []int
+	BackToBack []bool
+}
+
+func (s *GuidelineScorer) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(guidelineScorerState{
+		s.scores,
+		s.clears,
+		s.backToBack,
+	})
+	return buf.Bytes(), err
+}
+
+func (s *GuidelineScorer) UnmarshalBinary(data []byte) error {
+	var state guidelineScorerState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	s.scores = state.Scores
+	s.clears = state.Clears
+	s.backToBack = state.BackToBack
+	return nil
+}
@@ -0,0 +1,93 @@
+package game
+
+import "testing"
+
+func TestGuidelineScoresAreInitiallyZero(t *testing.T) {
+	s := NewGuidelineScorer()
+	checkInt(t, s.ScoreForPlayer(0), 0, "score")
+}
+
+func TestLineClearsAreScoredForThePlayer(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.BlocksLocked([]LockEvent{
+		{Player: 0, Lines: []int{0}},
+		{Player: 1, Lines: []int{0, 1, 2, 3}},
+	})
+	checkInt(t, s.ScoreForPlayer(0), 100, "single")
+	checkInt(t, s.ScoreForPlayer(1), 800, "four lines")
+}
+
+func TestTSpinsScoreMoreThanLineClears(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.BlocksLocked([]LockEvent{
+		{Player: 0, TSpin: MiniTSpin},
+		{Player: 1, Lines: []int{0, 1}, TSpin: FullTSpin},
+	})
+	checkInt(t, s.ScoreForPlayer(0), 100, "mini T-spin without lines")
+	checkInt(t, s.ScoreForPlayer(1), 1200, "T-spin double")
+}
+
+func TestLineScoresAreMultipliedByLevel(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.SetLevelFunc(func() int { return 3 })
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0, 1}}})
+	checkInt(t, s.ScoreForPlayer(0), 900, "double on level 3")
+}
+
+func TestDroppedFieldsAreScoredIndependentOfLevel(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.SetLevelFunc(func() int { return 5 })
+	s.BlocksLocked([]LockEvent{
+		{Player: 0, SoftDropDistance: 3, HardDropDistance: 4},
+	})
+	checkInt(t, s.ScoreForPlayer(0), 3+2*4, "drop score")
+}
+
+func TestConsecutiveLineClearsGiveComboBonus(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	checkInt(t, s.ScoreForPlayer(0), 100+150+200, "combo")
+	s.BlocksLocked([]LockEvent{{Player: 0}})
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	checkInt(t, s.ScoreForPlayer(0), 450+100, "combo broken")
+}
+
+func TestDifficultClearsBackToBackScoreHalfAgainAsMuch(t *testing.T) {
+	s := NewGuidelineScorer()
+	tetris := LockEvent{Player: 0, Lines: []int{0, 1, 2, 3}}
+	s.BlocksLocked([]LockEvent{tetris})
+	s.BlocksLocked([]LockEvent{{Player: 0}})
+	s.BlocksLocked([]LockEvent{tetris})
+	checkInt(t, s.ScoreForPlayer(0), 800+1200, "back-to-back")
+	s.BlocksLocked([]LockEvent{{Player: 0}})
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	s.BlocksLocked([]LockEvent{{Player: 0}})
+	s.BlocksLocked([]LockEvent{tetris})
+	checkInt(t, s.ScoreForPlayer(0), 2000+100+800, "broken by single")
+}
+
+func TestResettingClearsGuidelineScores(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	s.Reset()
+	checkInt(t, s.ScoreForPlayer(0), 0, "score")
+	s.BlocksLocked([]LockEvent{{Player: 0, Lines: []int{0}}})
+	checkInt(t, s.ScoreForPlayer(0), 100, "no combo after reset")
+}
+
+func TestGuidelineScoresCanBeMarshaled(t *testing.T) {
+	s := NewGuidelineScorer()
+	s.BlocksLocked([]LockEvent{{Player: 1, Lines: []int{0, 1, 2, 3}}})
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewGuidelineScorer()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	restored.BlocksLocked([]LockEvent{{Player: 1, Lines: []int{0, 1, 2, 3}}})
+	checkInt(t, restored.ScoreForPlayer(1), 800+1200+50, "back-to-back combo")
+}
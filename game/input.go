@@ -8,6 +8,42 @@ type InputEvent struct {
 
 type Command int
 
+// anyCommand is used to ask for a block control with any command.
+const anyCommand Command = -1
+
+// BlockControl lets the Player control the Block with the given Commands, or
+// with all of them if there are none. A control that allows pressing a key
+// also allows releasing it.
+type BlockControl struct {
+	Player   int
+	Block    int
+	Commands []Command
+}
+
+func (c BlockControl) allows(command Command) bool {
+	if len(c.Commands) == 0 || command == anyCommand {
+		return true
+	}
+	for _, allowed := range c.Commands {
+		if allowed == command || releaseCommand(allowed) == command {
+			return true
+		}
+	}
+	return false
+}
+
+func releaseCommand(c Command) Command {
+	switch c {
+	case DownPressed:
+		return DownReleased
+	case LeftPressed:
+		return LeftReleased
+	case RightPressed:
+		return RightReleased
+	}
+	return c
+}
+
 const (
 	DownPressed Command = iota
 	LeftPressed
@@ -18,4 +54,6 @@ const (
 	RotateLeft
 	RotateRight
 	Pause
+	HardDrop
+	Hold
 )
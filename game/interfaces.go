@@ -1,7 +1,8 @@
 package game
 
 type Board interface {
-	Size() (w, h int)
+	Size() (w, h int) // h includes the hidden rows above the visible ones
+	VisibleHeight() int
 	At(x, y int) int // the origin (0,0) is the bottom-left field
 	Copy() Board
 	SetAt(x, y, setTo int)
@@ -10,8 +11,16 @@ type Board interface {
 // NoPlayer is used in a Board to signal that a spot is empty.
 const NoPlayer = -1
 
+// Garbage is used in a Board for solid spots that were not placed by any
+// player but pushed in from below, see Versus.
+const Garbage = -2
+
 type BlockFactory func() Block
 
+// SeededBlockFactory creates a BlockFactory that always creates the same
+// sequence of blocks for the same seed, e.g. NewSevenBagRandomizer.
+type SeededBlockFactory func(seed int64) BlockFactory
+
 // DropTimer tells the game logic when it is time to drop all blocks at the same
 // time. This usually happens regularly and with higher frequency the higher the
 // difficulty.
@@ -21,6 +30,15 @@ type DropTimer interface {
 	IsTimeToDrop() bool
 }
 
+// LevelDropTimer is a DropTimer that drops faster on higher levels. If the
+// Logic's DropTimer implements it, the Logic passes it the removed lines, just
+// like it does to the Scorer, and reports its level.
+type LevelDropTimer interface {
+	DropTimer
+	Scorer
+	Level() int
+}
+
 type BlockCollisionObserver interface {
 	BlockHitLeftOrRight(block int)
 	BlockHitOtherBlock(block int)
@@ -35,6 +53,12 @@ type BlockMoveObserver interface {
 	BlockRotated(block int)
 }
 
+// BlockPushObserver is notified when a block is pushed up because garbage rows
+// were inserted below it.
+type BlockPushObserver interface {
+	BlockPushedUpByGarbage(block int)
+}
+
 type GameSoundPlayer interface {
 	BlockCollisionObserver
 	BlockMoveObserver
@@ -47,6 +71,69 @@ type LineAnimation interface {
 	IsRunning() bool
 }
 
+// GameOverObserver is notified when the game ends because the given block could
+// not be placed on the board after the previous one landed. By default, player
+// and block indices are the same, see Logic.AddBlockControl. If garbage pushes
+// solid fields over the top of the board, no block is to blame and the index is
+// NoPlayer.
+type GameOverObserver interface {
+	GameOver(player int)
+}
+
 type Scorer interface {
 	LinesRemoved(linesForPlayer [][]int)
 }
+
+// PlayerObserver is notified when players join or leave a running game, see
+// Logic.AddPlayer and Logic.RemovePlayer. If the Logic's Scorer implements it,
+// it is notified so it can keep its per player state in sync.
+type PlayerObserver interface {
+	PlayerAdded(player int)
+	PlayerRemoved(player int)
+}
+
+// LockScorer is a Scorer that also wants to know how the blocks were locked
+// into the board. If the Logic's Scorer implements it, BlocksLocked is called
+// after LinesRemoved in every frame in which blocks were locked, with one
+// LockEvent per locked block.
+type LockScorer interface {
+	Scorer
+	BlocksLocked(events []LockEvent)
+}
+
+// DropScorer is a Scorer that also scores players for moving their blocks
+// down. If the Logic's Scorer implements it, BlocksDropped is called in every
+// frame in which players moved their blocks down, with one DropEvent per such
+// player. Blocks moved down by the DropTimer are not reported.
+type DropScorer interface {
+	Scorer
+	BlocksDropped(events []DropEvent)
+}
+
+// DropEvent tells how many fields a player moved the block down in one frame,
+// by pressing or holding down (soft drop) or by a hard drop.
+type DropEvent struct {
+	Player           int
+	SoftDropDistance int
+	HardDropDistance int
+}
+
+// LockEvent describes how a player's block was locked into the board.
+// Lines are the full lines that the block is part of. The drop distances are
+// the number of fields that the player moved the block down by pressing down
+// (soft drop) or by a hard drop.
+type LockEvent struct {
+	Player           int
+	Lines            []int
+	TSpin            TSpin
+	SoftDropDistance int
+	HardDropDistance int
+}
+
+type TSpin int
+
+const (
+	NoTSpin TSpin = iota
+	MiniTSpin
+	FullTSpin
+)
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+)
+
+// SpeedCurve returns the number of updates between two drops on the given
+// level. It must be at least 1.
+type SpeedCurve func(level int) int
+
+// ClassicSpeedCurve is the NES speed table, given in frames at 60 frames per
+// second, starting at level 0.
+var ClassicSpeedCurve = TableSpeedCurve(
+	48, 43, 38, 33, 28, 23, 18, 13, 8, 6,
+	5, 5, 5, 4, 4, 4, 3, 3, 3,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	1,
+)
+
+// GuidelineSpeedCurve computes the drop speed at 60 frames per second with
+// the formula of the Tetris Guideline, starting at level 1.
+func GuidelineSpeedCurve(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	seconds := math.Pow(0.8-float64(level-1)*0.007, float64(level-1))
+	return atLeastOne(int(seconds*60 + 0.5))
+}
+
+// TableSpeedCurve uses the given frame counts for levels 0, 1, 2 and so on.
+// Levels after the end of the table use the last value.
+func TableSpeedCurve(frames ...int) SpeedCurve {
+	return func(level int) int {
+		if level < 0 {
+			level = 0
+		}
+		if level >= len(frames) {
+			level = len(frames) - 1
+		}
+		return atLeastOne(frames[level])
+	}
+}
+
+func atLeastOne(frames int) int {
+	if frames < 1 {
+		return 1
+	}
+	return frames
+}
+
+// LevelTimer is a LevelDropTimer which goes up one level every linesPerLevel
+// removed lines and uses a SpeedCurve to drop faster on higher levels.
+type LevelTimer struct {
+	curve         SpeedCurve
+	linesPerLevel int
+	startLevel    int
+	level         int
+	lines         int
+	timer         int
+}
+
+func NewLevelTimer(curve SpeedCurve, linesPerLevel int) *LevelTimer {
+	t := &LevelTimer{curve: curve, linesPerLevel: linesPerLevel}
+	t.Reset()
+	return t
+}
+
+// SetStartLevel sets the level that the timer starts at after the next Reset.
+func (t *LevelTimer) SetStartLevel(level int) {
+	t.startLevel = level
+}
+
+// Reset is called for each new game and goes back to the start level.
+func (t *LevelTimer) Reset() {
+	t.level = t.startLevel
+	t.lines = 0
+	t.timer = t.curve(t.level)
+}
+
+func (t *LevelTimer) Update() {
+	t.timer--
+	if t.timer < 0 {
+		t.timer = t.curve(t.level) - 1
+	}
+}
+
+func (t *LevelTimer) IsTimeToDrop() bool {
+	return t.timer == 0
+}
+
+func (t *LevelTimer) LinesRemoved(linesForPlayer [][]int) {
+	var all []int
+	for _, lines := range linesForPlayer {
+		all = append(all, lines...)
+	}
+	t.lines += countDistinct(all)
+	if t.linesPerLevel > 0 {
+		t.level = t.startLevel + t.lines/t.linesPerLevel
+	}
+}
+
+func (t *LevelTimer) Level() int {
+	return t.level
+}
+
+// Lines returns the number of lines removed since the last Reset.
+func (t *LevelTimer) Lines() int {
+	return t.lines
+}
+
+type levelTimerState struct {
+	StartLevel, Level, Lines, Timer int
+}
+
+func (t *LevelTimer) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	state := levelTimerState{t.startLevel, t.level, t.lines, t.timer}
+	err := gob.NewEncoder(&buf).Encode(state)
+	return buf.Bytes(), err
+}
+
+func (t *LevelTimer) UnmarshalBinary(data []byte) error {
+	var state levelTimerState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	t.startLevel = state.StartLevel
+	t.level = state.Level
+	t.lines = state.Lines
+	t.timer = state.Timer
+	return nil
+}
@@ -0,0 +1,83 @@
+package game
+
+import "testing"
+
+func TestLevelTimerDropsAccordingToSpeedCurve(t *testing.T) {
+	timer := NewLevelTimer(TableSpeedCurve(3), 10)
+	drops := ""
+	for i := 0; i < 9; i++ {
+		timer.Update()
+		if timer.IsTimeToDrop() {
+			drops += "x"
+		} else {
+			drops += "."
+		}
+	}
+	if drops != "..x..x..x" {
+		t.Error("wrong drop pattern", drops)
+	}
+}
+
+func TestLevelGoesUpWithRemovedLines(t *testing.T) {
+	timer := NewLevelTimer(TableSpeedCurve(3), 2)
+	checkInt(t, timer.Level(), 0, "initial level")
+	timer.LinesRemoved([][]int{{1}, {}})
+	checkInt(t, timer.Level(), 0, "one line removed")
+	timer.LinesRemoved([][]int{{1, 2}, {2, 3}})
+	checkInt(t, timer.Level(), 2, "four lines removed")
+	checkInt(t, timer.Lines(), 4, "lines")
+}
+
+func TestHigherLevelDropsFaster(t *testing.T) {
+	timer := NewLevelTimer(TableSpeedCurve(5, 2), 1)
+	timer.LinesRemoved([][]int{{0}})
+	updatesUntilDrop := 0
+	for i := 0; i < 20; i++ {
+		timer.Update()
+		if timer.IsTimeToDrop() {
+			updatesUntilDrop = 0
+		} else {
+			updatesUntilDrop++
+		}
+	}
+	checkInt(t, updatesUntilDrop, 1, "updates since last drop")
+}
+
+func TestResetGoesBackToStartLevel(t *testing.T) {
+	timer := NewLevelTimer(ClassicSpeedCurve, 1)
+	timer.SetStartLevel(5)
+	timer.Reset()
+	timer.LinesRemoved([][]int{{0, 1}})
+	checkInt(t, timer.Level(), 7, "level after two lines")
+	timer.Reset()
+	checkInt(t, timer.Level(), 5, "level after reset")
+	checkInt(t, timer.Lines(), 0, "lines after reset")
+}
+
+func TestSpeedCurves(t *testing.T) {
+	checkInt(t, ClassicSpeedCurve(0), 48, "classic level 0")
+	checkInt(t, ClassicSpeedCurve(9), 6, "classic level 9")
+	checkInt(t, ClassicSpeedCurve(29), 1, "classic level 29")
+	checkInt(t, ClassicSpeedCurve(100), 1, "classic level 100")
+	checkInt(t, GuidelineSpeedCurve(1), 60, "guideline level 1")
+	checkInt(t, GuidelineSpeedCurve(2), 48, "guideline level 2")
+	checkInt(t, GuidelineSpeedCurve(15), 1, "guideline level 15")
+	checkInt(t, TableSpeedCurve(5, 0)(1), 1, "table speed at least 1")
+}
+
+func TestLogicPassesRemovedLinesToLevelTimer(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 3}, []Point{{0, 2}})
+	timer := NewLevelTimer(TableSpeedCurve(100), 1)
+	logic.SetDropTimer(timer)
+	logic.StartNewGame(1)
+	checkInt(t, logic.Level(), 0, "initial level")
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	checkInt(t, logic.Level(), 1, "level after one line")
+}
+
+func TestLevelIsZeroWithoutLevelTimer(t *testing.T) {
+	logic, _ := createSpyDropTimerLogic()
+	logic.StartNewGame(1)
+	checkInt(t, logic.Level(), 0, "level")
+}
@@ -1,12 +1,24 @@
 package game
 
+import (
+	"errors"
+	"fmt"
+)
+
 type Logic struct {
 	blockFactory          BlockFactory
+	seededBlockFactory    SeededBlockFactory
+	seed                  int64
+	blocksCreated         int
 	physics               *physics
-	previewBlocks         []Block
+	previewQueues         [][]Block
+	previewCount          int
+	heldBlocks            []Block
+	unplacedBlocks        []Block
+	hasHeld               []bool
 	dropTimer             DropTimer
-	sizes                 [5]BoardSize
-	startPositions        [5][]Point
+	sizes                 map[int]BoardSize
+	startPositions        map[int][]Point
 	playerCount           int
 	hasDroppedThisFrame   []bool
 	lineAnimation         LineAnimation
@@ -14,18 +26,68 @@ type Logic struct {
 	leftKeys              []*repeatableKey
 	rightKeys             []*repeatableKey
 	downKeys              []*repeatableKey
+	downKeyPlayers        []int
 	initialLeftRightDelay int
 	initialDownDelay      int
 	shortLeftRightDelay   int
 	shortDownDelay        int
 	scorer                Scorer
 	soundPlayer           GameSoundPlayer
-}
+	gameOver              bool
+	gameOverObservers     []GameOverObserver
+	paused                bool
+	pausePolicy           PausePolicy
+	ghostsCollide         bool
+	lockDelay             int
+	lockResetOnMove       bool
+	maxLockResets         int
+	grounded              []bool
+	lockTimers            []int
+	lockResets            []int
+	lastMoveWasRotation   []bool
+	softDrops             []int
+	hardDrops             []int
+	frameSoftDrops        []int
+	frameHardDrops        []int
+	removedLines          []int
+	blockControls         []BlockControl
+	hiddenRows            int
+}
+
+// PausePolicy decides which players may pause and resume the game.
+type PausePolicy int
+
+const (
+	AnyPlayerMayPause PausePolicy = iota
+	OnlyHostMayPause
+)
+
+// Host is the player that may pause the game if the PausePolicy is
+// OnlyHostMayPause.
+const Host = 0
 
 func NewLogic(f BlockFactory) *Logic {
 	return &Logic{blockFactory: f}
 }
 
+// NewSeededLogic creates a Logic that creates a new BlockFactory from the seed
+// for every new game. Since the game logic itself is not random, a game started
+// with the same seed and updated with the same input events in every frame
+// always results in the same blocks, boards and scores.
+func NewSeededLogic(f SeededBlockFactory) *Logic {
+	return &Logic{seededBlockFactory: f}
+}
+
+// SetSeed sets the seed for the next call to StartNewGame. It is only used if
+// the Logic was created with NewSeededLogic.
+func (l *Logic) SetSeed(seed int64) {
+	l.seed = seed
+}
+
+func (l *Logic) Seed() int64 {
+	return l.seed
+}
+
 func (l *Logic) SetDropTimer(timer DropTimer) {
 	l.dropTimer = timer
 }
@@ -42,11 +104,118 @@ func (l *Logic) SetSoundPlayer(s GameSoundPlayer) {
 	l.soundPlayer = s
 }
 
+func (l *Logic) AddGameOverObserver(o GameOverObserver) {
+	l.gameOverObservers = append(l.gameOverObservers, o)
+}
+
+func (l *Logic) SetPausePolicy(p PausePolicy) {
+	l.pausePolicy = p
+}
+
+// SetPreviewCount sets how many upcoming blocks are known in advance for each
+// player. The default is 1.
+func (l *Logic) SetPreviewCount(count int) {
+	l.previewCount = count
+}
+
+// AddBlockControl lets a player control a block. By default, every player
+// controls the block with the same index with all commands. Once there are
+// BlockControls, players control only the blocks given in their BlockControls.
+// This way several players can share a block or one
+// player can control several blocks. Lines and drops are scored for all players
+// controlling the block.
+func (l *Logic) AddBlockControl(c BlockControl) {
+	l.blockControls = append(l.blockControls, c)
+}
+
+// ClearBlockControls restores the default of every player controlling the
+// block with the same index.
+func (l *Logic) ClearBlockControls() {
+	l.blockControls = nil
+}
+
+// players is the number of players, which can be larger than the number of
+// blocks if players were given BlockControls for shared blocks.
+func (l *Logic) players() int {
+	n := l.playerCount
+	for _, c := range l.blockControls {
+		if c.Player >= n {
+			n = c.Player + 1
+		}
+	}
+	return n
+}
+
+// blocksFor returns the blocks that the player controls with the command.
+func (l *Logic) blocksFor(player int, c Command) []int {
+	if len(l.blockControls) == 0 {
+		if 0 <= player && player < l.playerCount {
+			return []int{player}
+		}
+		return nil
+	}
+	var blocks []int
+	for _, control := range l.blockControls {
+		if control.Player == player && control.allows(c) &&
+			0 <= control.Block && control.Block < l.playerCount && !contains(blocks, control.Block) {
+			blocks = append(blocks, control.Block)
+		}
+	}
+	return blocks
+}
+
+// playersFor returns the players that control the block with the command.
+func (l *Logic) playersFor(block int, c Command) []int {
+	var players []int
+	for player := 0; player < l.players(); player++ {
+		if contains(l.blocksFor(player, c), block) {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// SetHiddenRows adds the given number of rows on top of the board sizes. They
+// are not meant to be drawn but blocks can move and lock there, e.g. when they
+// are pushed up by garbage or start above the visible board. Start positions
+// may be in the hidden rows.
+func (l *Logic) SetHiddenRows(rows int) {
+	l.hiddenRows = rows
+}
+
+// SetLockDelay sets the number of updates that a block stays movable after it
+// hit the ground. The default of 0 means that blocks land immediately. Hard
+// dropped blocks always land immediately.
+func (l *Logic) SetLockDelay(updates int) {
+	l.lockDelay = updates
+}
+
+// SetLockDelayResetOnMove makes a grounded block's lock delay start over
+// whenever it is moved horizontally or rotated.
+func (l *Logic) SetLockDelayResetOnMove(reset bool) {
+	l.lockResetOnMove = reset
+}
+
+// SetMaxLockDelayResets limits how often the lock delay of a block can start
+// over. A value of 0 or less means there is no limit.
+func (l *Logic) SetMaxLockDelayResets(resets int) {
+	l.maxLockResets = resets
+}
+
 func (l *Logic) SetBoardSizeForPlayerCount(players int, size BoardSize) {
+	if l.sizes == nil {
+		l.sizes = make(map[int]BoardSize)
+	}
 	l.sizes[players] = size
 }
 
+// SetBlockStartPositions sets where the players' blocks start when playing
+// with the given number of players. Without start positions, the blocks start
+// evenly spread across the top of the board.
 func (l *Logic) SetBlockStartPositions(players int, start []Point) {
+	if l.startPositions == nil {
+		l.startPositions = make(map[int][]Point)
+	}
 	l.startPositions[players] = start
 }
 
@@ -68,24 +237,167 @@ func (l *Logic) SetShortDownKeyDelay(delay int) {
 	l.shortDownDelay = delay
 }
 
-func (l *Logic) StartNewGame(players int) {
+// StartNewGame validates the configuration for the given number of players and
+// starts a new game. It also checks the blocks that the block factory creates
+// for the preview queues, blocks created later in the game are not checked. If
+// there is an error, no game is started and the current game is left as it is.
+// Only a factory that is not seeded will have created blocks in that case.
+func (l *Logic) StartNewGame(players int) error {
+	if err := l.Validate(players); err != nil {
+		return err
+	}
+	factory := l.newBlockFactory()
+	queues, err := l.createPreviewQueues(factory, players)
+	if err != nil {
+		return err
+	}
+	l.blockFactory = factory
+	l.blocksCreated = players * l.previewQueueLength()
 	l.playerCount = players
+	l.gameOver = false
+	l.paused = false
 	l.hasDroppedThisFrame = make([]bool, players)
-	l.physics = newPhysics(l.sizes[players], BlockCount(players))
+	l.createPhysics(l.sizes[players])
+	l.createBlocks(queues)
+	l.createRepeatableKeys()
+	return nil
+}
+
+// Validate checks that a game with the given number of players can be started
+// with the current configuration. The board size has to be set, start
+// positions, if set, have to lie on the board, one for each player, and no key
+// delay may be negative. Empty start positions count as not set. BlockControls
+// must refer to existing blocks and may not have negative players.
+func (l *Logic) Validate(players int) error {
+	if err := l.validateFor(players); err != nil {
+		return err
+	}
+	for _, c := range l.blockControls {
+		if c.Block < 0 || c.Block >= players {
+			return fmt.Errorf("block control for block %v with %v players",
+				c.Block, players)
+		}
+		if c.Player < 0 {
+			return fmt.Errorf("block control for player %v", c.Player)
+		}
+	}
+	return nil
+}
+
+// validateFor checks the configuration for the given number of players, without
+// the BlockControls. These are renumbered only after players leave the game.
+func (l *Logic) validateFor(players int) error {
+	if players < 1 {
+		return fmt.Errorf("invalid number of players: %v", players)
+	}
+	if l.blockFactory == nil && l.seededBlockFactory == nil {
+		return errors.New("no block factory set")
+	}
+	size := l.sizes[players]
+	if size.Width <= 0 || size.Height <= 0 {
+		return fmt.Errorf("no board size set for %v players", players)
+	}
+	if l.hiddenRows < 0 {
+		return fmt.Errorf("invalid number of hidden rows: %v", l.hiddenRows)
+	}
+	if starts := l.startPositions[players]; len(starts) > 0 {
+		if len(starts) != players {
+			return fmt.Errorf("%v start positions set for %v players",
+				len(starts), players)
+		}
+		for i, p := range starts {
+			if p.X < 0 || p.X >= size.Width ||
+				p.Y < 0 || p.Y >= size.Height+l.hiddenRows {
+				return fmt.Errorf("start position %v of player %v is outside the %vx%v board",
+					p, i, size.Width, size.Height+l.hiddenRows)
+			}
+		}
+	}
+	if l.initialLeftRightDelay < 0 || l.shortLeftRightDelay < 0 ||
+		l.initialDownDelay < 0 || l.shortDownDelay < 0 {
+		return errors.New("key delays must not be negative")
+	}
+	return nil
+}
+
+func (l *Logic) resetBlockFactory() {
+	l.blockFactory = l.newBlockFactory()
+	l.blocksCreated = 0
+}
+
+// newBlockFactory creates a new factory from the seed if the Logic was created
+// with a SeededBlockFactory. Otherwise it returns the current one.
+func (l *Logic) newBlockFactory() BlockFactory {
+	if l.seededBlockFactory != nil {
+		return l.seededBlockFactory(l.seed)
+	}
+	return l.blockFactory
+}
+
+func (l *Logic) createPhysics(size BoardSize) {
+	l.physics = newPhysicsWithHiddenRows(size, l.hiddenRows, BlockCount(l.playerCount))
 	l.physics.AddCollisionObserver(l)
+	l.physics.AddBlockMoveObserver(l)
 	if l.soundPlayer != nil {
 		l.physics.AddCollisionObserver(l.soundPlayer)
 		l.physics.AddBlockMoveObserver(l.soundPlayer)
+		if o, ok := l.soundPlayer.(BlockPushObserver); ok {
+			l.physics.AddBlockPushObserver(o)
+		}
 	}
-	l.createBlocks()
-	l.createRepeatableKeys()
 }
 
-func (l *Logic) createBlocks() {
-	l.previewBlocks = make([]Block, l.playerCount)
-	for i := range l.previewBlocks {
-		l.previewBlocks[i] = l.blockFactory()
+// createPreviewQueues fills the preview queues in turns, like the blocks would
+// be handed out to the players. It returns an error if the block factory
+// creates an invalid block.
+func (l *Logic) createPreviewQueues(factory BlockFactory, players int) ([][]Block, error) {
+	queues := make([][]Block, players)
+	for i := 0; i < l.previewQueueLength(); i++ {
+		for player := range queues {
+			b := factory()
+			if err := validateBlock(b); err != nil {
+				return nil, fmt.Errorf("block factory created an invalid block: %v", err)
+			}
+			queues[player] = append(queues[player], b)
+		}
+	}
+	return queues, nil
+}
+
+func validateBlock(b Block) error {
+	if len(b.Points) == 0 {
+		return errors.New("block has no points")
+	}
+	return checkRotations(b)
+}
+
+// checkRotations makes sure that there are deltas for all of the block's points
+// and kicks for all of its rotations.
+func checkRotations(b Block) error {
+	for i, deltas := range b.RotationDeltas {
+		if len(deltas) != len(b.Points) {
+			return fmt.Errorf("rotation %v has %v deltas for %v points",
+				i, len(deltas), len(b.Points))
+		}
+	}
+	if len(b.Kicks) != 0 && len(b.Kicks) != len(b.RotationDeltas) {
+		return fmt.Errorf("block has kicks for %v of its %v rotations",
+			len(b.Kicks), len(b.RotationDeltas))
 	}
+	return nil
+}
+
+func (l *Logic) createBlocks(queues [][]Block) {
+	l.heldBlocks = make([]Block, l.playerCount)
+	l.unplacedBlocks = make([]Block, l.playerCount)
+	l.hasHeld = make([]bool, l.playerCount)
+	l.grounded = make([]bool, l.playerCount)
+	l.lockTimers = make([]int, l.playerCount)
+	l.lockResets = make([]int, l.playerCount)
+	l.lastMoveWasRotation = make([]bool, l.playerCount)
+	l.softDrops = make([]int, l.playerCount)
+	l.hardDrops = make([]int, l.playerCount)
+	l.previewQueues = queues
 	for i := 0; i < l.playerCount; i++ {
 		l.resetBlockToPreview(i)
 	}
@@ -94,10 +406,18 @@ func (l *Logic) createBlocks() {
 	}
 }
 
+func (l *Logic) previewQueueLength() int {
+	if l.previewCount < 1 {
+		return 1
+	}
+	return l.previewCount
+}
+
 func (l *Logic) createRepeatableKeys() {
 	l.leftKeys = l.makeKeys(l.initialLeftRightDelay, l.shortLeftRightDelay)
 	l.rightKeys = l.makeKeys(l.initialLeftRightDelay, l.shortLeftRightDelay)
 	l.downKeys = l.makeKeys(l.initialDownDelay, l.shortDownDelay)
+	l.downKeyPlayers = make([]int, l.playerCount)
 }
 
 func (l *Logic) makeKeys(initialDelay, fastDelay int) []*repeatableKey {
@@ -108,59 +428,228 @@ func (l *Logic) makeKeys(initialDelay, fastDelay int) []*repeatableKey {
 	return keys
 }
 
-func (l *Logic) startPositionFor(index int) Point {
-	return Point{0, 0}
+// startPositionFor returns the configured start position of the block or, if
+// there is none, spreads the blocks evenly across the board, two rows below the
+// top of the visible board.
+func (l *Logic) startPositionFor(block int) Point {
+	if starts := l.startPositions[l.playerCount]; block < len(starts) {
+		return starts[block]
+	}
+	size := l.sizes[l.playerCount]
+	y := size.Height - 2
+	if y < 0 {
+		y = 0
+	}
+	return Point{(2*block + 1) * size.Width / (2 * l.playerCount), y}
 }
 
 func (l *Logic) Blocks() []Block {
 	return l.physics.Blocks()
 }
 
+// GhostBlocks returns, for each player, a copy of the current block at the
+// position where it would land if it was hard dropped. By default other players'
+// blocks are ignored since they keep moving, see SetGhostsCollideWithOtherBlocks.
+func (l *Logic) GhostBlocks() []Block {
+	ghosts := make([]Block, len(l.physics.Blocks()))
+	for i := range ghosts {
+		ghosts[i] = l.physics.Ghost(i, l.ghostsCollide)
+	}
+	return ghosts
+}
+
+// SetGhostsCollideWithOtherBlocks makes GhostBlocks land on top of the other
+// players' current blocks.
+func (l *Logic) SetGhostsCollideWithOtherBlocks(collide bool) {
+	l.ghostsCollide = collide
+}
+
+// PreviewBlocks returns the next block for each player.
 func (l *Logic) PreviewBlocks() []Block {
-	return l.previewBlocks
+	next := make([]Block, len(l.previewQueues))
+	for player, queue := range l.previewQueues {
+		next[player] = queue[0]
+	}
+	return next
+}
+
+// PreviewQueue returns the upcoming blocks of the given player, the next one
+// first. Its length is set with SetPreviewCount.
+func (l *Logic) PreviewQueue(player int) []Block {
+	return l.previewQueues[player]
+}
+
+// HeldBlocks returns the block that each player put aside with the Hold
+// command. It is an empty Block if a player has not held a block yet.
+func (l *Logic) HeldBlocks() []Block {
+	return l.heldBlocks
+}
+
+// Level returns the level of the DropTimer if it is a LevelDropTimer and 0
+// otherwise.
+func (l *Logic) Level() int {
+	if timer, ok := l.dropTimer.(LevelDropTimer); ok {
+		return timer.Level()
+	}
+	return 0
 }
 
 func (l *Logic) Board() Board {
 	return l.physics.Board()
 }
 
+// IsGameOver is true once a new block could not be placed on the board. After
+// that Update does nothing until the next call to StartNewGame.
+func (l *Logic) IsGameOver() bool {
+	return l.gameOver
+}
+
+// IsPaused is true after a player sent a Pause event and until a player sends
+// the next one. While paused, blocks, key repeats, the drop timer and the line
+// animation do not change. Only key releases are registered so that no keys
+// are stuck when the game resumes.
+func (l *Logic) IsPaused() bool {
+	return l.paused
+}
+
 func (l *Logic) Update(events ...InputEvent) {
+	l.removedLines = nil
+	if l.gameOver {
+		return
+	}
+	l.handlePauseEvents(events...)
+	if l.paused {
+		l.handleReleaseEvents(events...)
+		return
+	}
 	if l.lineAnimation != nil && l.lineAnimation.IsRunning() {
 		l.handleReleaseEvents(events...)
 		l.lineAnimation.Update()
 		return
 	}
 	l.giveScoresForFullLines()
-	l.resetPreviouslyDroppedBlocks()
+	dropped := l.solidifyPreviouslyDroppedBlocks()
 	l.removeFullLines()
-	l.handleInputEvents(events...)
-	l.dropBlocksIfTimeForIt()
-	l.checkCompleteLines()
+	l.resetBlocks(dropped)
+	if !l.gameOver {
+		l.updateLockDelays()
+		l.handleInputEvents(events...)
+		l.giveScoresForDrops()
+		l.dropBlocksIfTimeForIt()
+		l.checkCompleteLines()
+	}
 	if l.soundPlayer != nil {
 		l.soundPlayer.PlaySounds()
 	}
 }
 
+// handlePauseEvents toggles the pause state at most once per Update, so players
+// pressing Pause at the same time do not cancel each other out.
+func (l *Logic) handlePauseEvents(events ...InputEvent) {
+	for _, e := range events {
+		if e.Command == Pause && l.mayPause(e.Player) {
+			l.paused = !l.paused
+			return
+		}
+	}
+}
+
+func (l *Logic) mayPause(player int) bool {
+	if l.pausePolicy == OnlyHostMayPause {
+		return player == Host
+	}
+	return 0 <= player && player < l.players()
+}
+
 func (l *Logic) giveScoresForFullLines() {
+	blockLines := make([][]int, l.playerCount)
+	l.fillWithBlockToLineInfo(blockLines)
+	lines := l.linesForPlayers(blockLines)
 	if l.scorer != nil {
-		lines := make([][]int, l.playerCount)
-		l.fillWithPlayerToLineInfo(lines)
 		l.scorer.LinesRemoved(lines)
 	}
+	if scorer, ok := l.scorer.(LockScorer); ok {
+		if events := l.lockEvents(blockLines); len(events) > 0 {
+			scorer.BlocksLocked(events)
+		}
+	}
+	if timer, ok := l.dropTimer.(LevelDropTimer); ok {
+		timer.LinesRemoved(lines)
+	}
 }
 
-func (l *Logic) fillWithPlayerToLineInfo(lines [][]int) {
+// linesForPlayers credits the lines of each block to all players that control
+// the block.
+func (l *Logic) linesForPlayers(blockLines [][]int) [][]int {
+	lines := make([][]int, l.players())
+	for block, blockLines := range blockLines {
+		for _, player := range l.playersFor(block, anyCommand) {
+			lines[player] = append(lines[player], blockLines...)
+		}
+	}
+	return lines
+}
+
+func (l *Logic) lockEvents(blockLines [][]int) []LockEvent {
+	var events []LockEvent
+	for block := 0; block < l.playerCount; block++ {
+		if l.hasDroppedThisFrame[block] {
+			for _, player := range l.playersFor(block, anyCommand) {
+				events = append(events, LockEvent{
+					Player:           player,
+					Lines:            blockLines[block],
+					TSpin:            l.tSpin(block),
+					SoftDropDistance: l.softDrops[block],
+					HardDropDistance: l.hardDrops[block],
+				})
+			}
+		}
+	}
+	return events
+}
+
+// tSpin uses the three corner rule: a T block that was rotated into place is a
+// T-spin if at least three of the four fields diagonal to its center are
+// solid. It is a full T-spin if both corners on the side that the T points to
+// are solid and a mini T-spin otherwise.
+func (l *Logic) tSpin(block int) TSpin {
+	b := l.Blocks()[block]
+	if b.Shape != TShape || len(b.Points) != 4 || !l.lastMoveWasRotation[block] {
+		return NoTSpin
+	}
+	center, nub := b.Points[0], b.Points[2]
+	facing := Point{nub.X - center.X, nub.Y - center.Y}
+	front, back := 0, 0
+	for _, c := range []Point{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		if l.physics.isSolidAt(center.X+c.X, center.Y+c.Y) {
+			if c.X*facing.X+c.Y*facing.Y > 0 {
+				front++
+			} else {
+				back++
+			}
+		}
+	}
+	if front+back < 3 {
+		return NoTSpin
+	}
+	if front == 2 {
+		return FullTSpin
+	}
+	return MiniTSpin
+}
+
+func (l *Logic) fillWithBlockToLineInfo(lines [][]int) {
 	for _, line := range l.fullLines {
-		for player := 0; player < l.playerCount; player++ {
-			if l.playerIsDroppedInLine(player, line) {
-				lines[player] = append(lines[player], line)
+		for block := 0; block < l.playerCount; block++ {
+			if l.blockIsDroppedInLine(block, line) {
+				lines[block] = append(lines[block], line)
 			}
 		}
 	}
 }
 
-func (l *Logic) playerIsDroppedInLine(player, line int) bool {
-	return l.hasDroppedThisFrame[player] && l.blockIsInLine(player, line)
+func (l *Logic) blockIsDroppedInLine(block, line int) bool {
+	return l.hasDroppedThisFrame[block] && l.blockIsInLine(block, line)
 }
 
 func (l *Logic) blockIsInLine(block, line int) bool {
@@ -180,91 +669,199 @@ func (l *playerToLines) LinesForPlayer(p int) []int {
 	return l.lines[p]
 }
 
-func (l *Logic) resetPreviouslyDroppedBlocks() {
+// solidifyPreviouslyDroppedBlocks copies the dropped blocks to the board and
+// takes them out of the physics so that they are not dragged along when lines
+// are removed. They are replaced only after the lines are removed, see
+// resetBlocks.
+func (l *Logic) solidifyPreviouslyDroppedBlocks() (dropped []int) {
 	for b := 0; b < l.playerCount; b++ {
 		if l.hasDroppedThisFrame[b] {
 			l.physics.CopyBlockToBoard(b)
-			l.resetBlockToPreview(b)
-			for l.physics.isInOtherBlock(b) {
-				l.physics.Blocks()[b].MoveBy(0, 1)
-			}
-			l.downKeys[b].Release()
-			l.hasDroppedThisFrame[b] = false
+			l.physics.SetBlock(b, Block{})
+			dropped = append(dropped, b)
+		}
+	}
+	return
+}
+
+// resetBlocks is called after full lines were removed so that lines that are
+// removed in this frame do not end the game.
+func (l *Logic) resetBlocks(blocks []int) {
+	for _, b := range blocks {
+		l.resetBlockToPreview(b)
+		l.downKeys[b].Release()
+		l.hasDroppedThisFrame[b] = false
+		l.hasHeld[b] = false
+		if !l.moveUpOutOfOtherBlocks(b) {
+			l.endGame(b)
+		}
+	}
+}
+
+// moveUpOutOfOtherBlocks pushes a newly placed block up until it does not
+// collide with any other block. It returns false if the block overlaps the
+// solid part of the board or would be pushed above the top of the board.
+func (l *Logic) moveUpOutOfOtherBlocks(block int) bool {
+	if l.physics.isInSolidPartOfBoard(block) {
+		return false
+	}
+	for l.physics.isInOtherBlock(block) {
+		l.physics.Blocks()[block].MoveBy(0, 1)
+		if l.physics.isAboveTop(block) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *Logic) endGame(player int) {
+	if !l.gameOver {
+		l.gameOver = true
+		for _, o := range l.gameOverObservers {
+			o.GameOver(player)
 		}
 	}
 }
 
 func (l *Logic) resetBlockToPreview(block int) {
-	b := l.previewBlocks[block]
-	start := l.startPositions[l.playerCount][block]
+	queue := l.previewQueues[block]
+	l.placeAtStart(block, queue[0])
+	copy(queue, queue[1:])
+	queue[len(queue)-1] = l.newBlock()
+}
+
+func (l *Logic) newBlock() Block {
+	l.blocksCreated++
+	return l.blockFactory()
+}
+
+func (l *Logic) placeAtStart(block int, b Block) {
+	l.unplacedBlocks[block] = b.Copy()
+	start := l.startPositionFor(block)
 	w, _ := b.Size()
 	b.MoveBy(start.X-w/2, start.Y)
 	l.physics.SetBlock(block, b)
-	l.previewBlocks[block] = l.blockFactory()
+	l.grounded[block] = false
+	l.lockResets[block] = 0
+	l.lastMoveWasRotation[block] = false
+	l.softDrops[block] = 0
+	l.hardDrops[block] = 0
+}
+
+// hold puts the player's current block aside and replaces it with the one held
+// before, or with the next preview block if there is none. This is possible
+// only once until the player's block lands.
+func (l *Logic) hold(player int) {
+	if l.hasHeld[player] {
+		return
+	}
+	l.hasHeld[player] = true
+	held := l.heldBlocks[player]
+	l.heldBlocks[player] = l.unplacedBlocks[player]
+	if len(held.Points) == 0 {
+		l.resetBlockToPreview(player)
+	} else {
+		l.placeAtStart(player, held)
+	}
+	if !l.moveUpOutOfOtherBlocks(player) {
+		l.endGame(player)
+	}
 }
 
 func (l *Logic) removeFullLines() {
+	if len(l.fullLines) > 0 {
+		l.removedLines = append([]int{}, l.fullLines...)
+	}
 	l.physics.RemoveLines(l.fullLines...)
 }
 
+// RemovedLines returns the lines that were removed from the board in the last
+// Update.
+func (l *Logic) RemovedLines() []int {
+	return l.removedLines
+}
+
 func (l *Logic) handleReleaseEvents(events ...InputEvent) {
 	for _, e := range events {
-		if e.Player < l.playerCount {
+		for _, b := range l.blocksFor(e.Player, e.Command) {
 			switch e.Command {
 			case DownReleased:
-				l.downKeys[e.Player].Release()
+				l.downKeys[b].Release()
 			case LeftReleased:
-				l.leftKeys[e.Player].Release()
+				l.leftKeys[b].Release()
 			case RightReleased:
-				l.rightKeys[e.Player].Release()
+				l.rightKeys[b].Release()
 			}
 		}
 	}
 }
 
 func (l *Logic) handleInputEvents(events ...InputEvent) {
+	l.frameSoftDrops = make([]int, l.players())
+	l.frameHardDrops = make([]int, l.players())
 	l.handleKeyRepeatEvents()
 
 	for _, e := range events {
-		if e.Player < l.playerCount {
-			switch e.Command {
+		for _, b := range l.blocksFor(e.Player, e.Command) {
+			l.handleCommand(e.Player, b, e.Command)
+		}
+	}
+}
 
-			case DownPressed:
-				if !l.hasDroppedThisFrame[e.Player] && l.downKeys[e.Player].Press() {
-					l.physics.MoveDown(e.Player)
-				}
-			case DownReleased:
-				l.downKeys[e.Player].Release()
+func (l *Logic) handleCommand(player, block int, c Command) {
+	switch c {
 
-			case LeftPressed:
-				if !l.hasDroppedThisFrame[e.Player] && l.leftKeys[e.Player].Press() {
-					if !l.physics.MoveLeft(e.Player) {
-						l.leftKeys[e.Player].Blocked()
-					}
-				}
-			case LeftReleased:
-				l.leftKeys[e.Player].Release()
+	case DownPressed:
+		if !l.hasDroppedThisFrame[block] && l.downKeys[block].Press() {
+			l.downKeyPlayers[block] = player
+			l.softDrop(block, player)
+		}
+	case DownReleased:
+		l.downKeys[block].Release()
 
-			case RightPressed:
-				if !l.hasDroppedThisFrame[e.Player] && l.rightKeys[e.Player].Press() {
-					if !l.physics.MoveRight(e.Player) {
-						l.rightKeys[e.Player].Blocked()
-					}
-				}
-			case RightReleased:
-				l.rightKeys[e.Player].Release()
+	case LeftPressed:
+		if !l.hasDroppedThisFrame[block] && l.leftKeys[block].Press() {
+			if !l.physics.MoveLeft(block) {
+				l.leftKeys[block].Blocked()
+			}
+		}
+	case LeftReleased:
+		l.leftKeys[block].Release()
 
-			case RotateRight:
-				l.physics.RotateRight(e.Player)
-			case RotateLeft:
-				l.physics.RotateLeft(e.Player)
+	case RightPressed:
+		if !l.hasDroppedThisFrame[block] && l.rightKeys[block].Press() {
+			if !l.physics.MoveRight(block) {
+				l.rightKeys[block].Blocked()
 			}
 		}
+	case RightReleased:
+		l.rightKeys[block].Release()
+
+	case HardDrop:
+		if !l.hasDroppedThisFrame[block] {
+			l.hardDrop(block, player)
+		}
+	case Hold:
+		if !l.hasDroppedThisFrame[block] {
+			l.hold(block)
+		}
+
+	case RotateRight:
+		if !l.hasDroppedThisFrame[block] {
+			l.physics.RotateRight(block)
+		}
+	case RotateLeft:
+		if !l.hasDroppedThisFrame[block] {
+			l.physics.RotateLeft(block)
+		}
 	}
 }
 
 func (l *Logic) handleKeyRepeatEvents() {
 	for i := 0; i < l.playerCount; i++ {
+		if l.hasDroppedThisFrame[i] {
+			continue
+		}
 		if l.rightKeys[i].Update() {
 			l.physics.MoveRight(i)
 		}
@@ -272,9 +869,46 @@ func (l *Logic) handleKeyRepeatEvents() {
 			l.physics.MoveLeft(i)
 		}
 		if l.downKeys[i].Update() {
-			l.physics.MoveDown(i)
+			l.softDrop(i, l.downKeyPlayers[i])
+		}
+	}
+}
+
+// softDrop moves the block down like pressing down does, as opposed to the
+// drop timer, and counts the field for the player's score.
+func (l *Logic) softDrop(block, player int) {
+	if l.physics.MoveDown(block) {
+		l.softDrops[block]++
+		l.frameSoftDrops[player]++
+	}
+}
+
+func (l *Logic) hardDrop(block, player int) {
+	distance := l.physics.HardDrop(block)
+	l.hardDrops[block] += distance
+	l.frameHardDrops[player] += distance
+	l.hasDroppedThisFrame[block] = true
+}
+
+func (l *Logic) giveScoresForDrops() {
+	scorer, ok := l.scorer.(DropScorer)
+	if !ok {
+		return
+	}
+	var events []DropEvent
+	for player := 0; player < l.players(); player++ {
+		soft, hard := l.frameSoftDrops[player], l.frameHardDrops[player]
+		if soft > 0 || hard > 0 {
+			events = append(events, DropEvent{
+				Player:           player,
+				SoftDropDistance: soft,
+				HardDropDistance: hard,
+			})
 		}
 	}
+	if len(events) > 0 {
+		scorer.BlocksDropped(events)
+	}
 }
 
 func (l *Logic) dropBlocksIfTimeForIt() {
@@ -333,8 +967,53 @@ func lineFull(b Board, y, w int) bool {
 	return true
 }
 
+// updateLockDelays lands the grounded blocks whose lock delay is over. Blocks
+// that were moved off the ground in the meantime fall again instead.
+func (l *Logic) updateLockDelays() {
+	for b, grounded := range l.grounded {
+		if grounded && !l.hasDroppedThisFrame[b] {
+			l.lockTimers[b]--
+			if l.lockTimers[b] <= 0 {
+				if l.physics.canMoveDown(b) {
+					l.grounded[b] = false
+				} else {
+					l.hasDroppedThisFrame[b] = true
+				}
+			}
+		}
+	}
+}
+
+func (l *Logic) resetLockDelay(block int) {
+	if l.grounded[block] && l.lockResetOnMove &&
+		(l.maxLockResets <= 0 || l.lockResets[block] < l.maxLockResets) {
+		l.lockTimers[block] = l.lockDelay
+		l.lockResets[block]++
+	}
+}
+
 func (l *Logic) BlockHitGround(block int) {
-	l.hasDroppedThisFrame[block] = true
+	if l.lockDelay <= 0 {
+		l.hasDroppedThisFrame[block] = true
+	} else if !l.grounded[block] {
+		l.grounded[block] = true
+		l.lockTimers[block] = l.lockDelay
+	}
+}
+
+func (l *Logic) BlockMovedHorizontally(block int) {
+	l.resetLockDelay(block)
+	l.lastMoveWasRotation[block] = false
+}
+
+func (l *Logic) BlockRotated(block int) {
+	l.resetLockDelay(block)
+	l.lastMoveWasRotation[block] = true
+}
+
+func (l *Logic) BlockMovedDown(block int) {
+	l.grounded[block] = false
+	l.lastMoveWasRotation[block] = false
 }
 
 func (l *Logic) BlockCouldNotRotate(block int)           {}
@@ -7,7 +7,7 @@ import (
 )
 
 func TestBoardSizeIsSetPerPlayerCount(t *testing.T) {
-	logic := NewLogic(alwaysReturn(block()))
+	logic := NewLogic(alwaysReturn(block(0, 0)))
 	logic.SetBoardSizeForPlayerCount(1, BoardSize{10, 18})
 	logic.SetBlockStartPositions(1, []Point{{0, 0}})
 	logic.SetBoardSizeForPlayerCount(2, BoardSize{12, 20})
@@ -64,8 +64,119 @@ func TestBlocksAreXCenteredOnStartPosition(t *testing.T) {
 	checkGame(t, logic, "width = 3", "..000..")
 }
 
+func TestGameCanNotStartWithoutBoardSize(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 4})
+	if err := logic.StartNewGame(2); err == nil {
+		t.Error("game started without board size for 2 players")
+	}
+	if err := logic.StartNewGame(0); err == nil {
+		t.Error("game started without players")
+	}
+	if err := logic.StartNewGame(1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStartPositionsMustBeOnBoardForEveryPlayer(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{4, 4})
+	logic.SetHiddenRows(2)
+
+	logic.SetBlockStartPositions(2, []Point{{0, 0}})
+	if err := logic.StartNewGame(2); err == nil {
+		t.Error("game started with too few start positions")
+	}
+	logic.SetBlockStartPositions(2, []Point{{0, 0}, {4, 0}})
+	if err := logic.StartNewGame(2); err == nil {
+		t.Error("game started with start position right of the board")
+	}
+	logic.SetBlockStartPositions(2, []Point{{0, -1}, {3, 0}})
+	if err := logic.StartNewGame(2); err == nil {
+		t.Error("game started with start position below the board")
+	}
+	logic.SetBlockStartPositions(2, []Point{{0, 0}, {3, 5}})
+	if err := logic.StartNewGame(2); err != nil {
+		t.Error("start position in hidden rows rejected:", err)
+	}
+}
+
+func TestBlockControlsMustReferToExistingBlocks(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 4})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: -1})
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with control for block -1")
+	}
+	logic.ClearBlockControls()
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 1})
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with control for block 1 of 1")
+	}
+	logic.ClearBlockControls()
+	logic.AddBlockControl(BlockControl{Player: -1, Block: 0})
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with control for player -1")
+	}
+}
+
+func TestKeyDelaysMustNotBeNegative(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 4})
+	logic.SetShortDownKeyDelay(-1)
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with negative key delay")
+	}
+}
+
+func TestBlockFactoryMustCreateValidBlocks(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block()))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 4})
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with empty block")
+	}
+
+	b := block(0, 0, 1, 0)
+	b.RotationDeltas = [][]Point{{{0, 0}, {-1, 1}}, {{0, 0}}}
+	logic = NewLogic(alwaysReturn(b))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 4})
+	if err := logic.StartNewGame(1); err == nil {
+		t.Error("game started with missing rotation deltas")
+	}
+}
+
+func TestFailedStartKeepsCurrentGame(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{3, 1})
+	logic.SetBlockStartPositions(1, []Point{{1, 0}})
+	logic.StartNewGame(1)
+	if err := logic.StartNewGame(2); err == nil {
+		t.Fatal("game started without board size for 2 players")
+	}
+	checkGame(t, logic, "running game", ".0.")
+}
+
+func TestFailedStartKeepsBlockFactory(t *testing.T) {
+	logic := NewSeededLogic(func(seed int64) BlockFactory {
+		if seed == 1 {
+			return alwaysReturn(block())
+		}
+		return increasingYBlocks(1)
+	})
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{20, 20})
+	logic.SetBlockStartPositions(1, []Point{{10, 10}})
+	logic.StartNewGame(1)
+	logic.SetSeed(1)
+	if err := logic.StartNewGame(1); err == nil {
+		t.Fatal("game started with empty blocks")
+	}
+	logic.Update(InputEvent{0, Hold})
+	checkBlocksEqual(t, logic.PreviewBlocks(), block(0, 3))
+}
+
 func TestNewGameCreatesNewPreviewBlock(t *testing.T) {
 	l := NewLogic(increasingYBlocks(1))
+	l.SetBoardSizeForPlayerCount(1, BoardSize{20, 20})
 	l.SetBlockStartPositions(1, []Point{{10, 10}})
 	l.StartNewGame(1)
 	checkBlocksEqual(t, l.PreviewBlocks(), block(0, 2))
@@ -201,7 +312,7 @@ func TestOnUpdateTheDropTimerIsUpdated(t *testing.T) {
 }
 
 func createSpyDropTimerLogic() (*Logic, *spyDropTimer) {
-	logic := NewLogic(alwaysReturn(block()))
+	logic := NewLogic(alwaysReturn(block(0, 0)))
 	spy := &spyDropTimer{}
 	logic.SetDropTimer(spy)
 	logic.SetBoardSizeForPlayerCount(1, BoardSize{1, 1})
@@ -863,6 +974,758 @@ func TestAdjacentBlocksAreFollowedIfHoldingRightDown(t *testing.T) {
 	// dropping.
 }
 
+func TestNewBlockOverlappingSolidBoardEndsTheGame(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 1}})
+	spy := &spyGameOverObserver{}
+	logic.AddGameOverObserver(spy)
+	logic.StartNewGame(1)
+	logic.Board().SetAt(0, 0, 0)
+	logic.Update(InputEvent{0, DownPressed})
+	if logic.IsGameOver() {
+		t.Fatal("game over too early")
+	}
+	logic.Board().SetAt(0, 1, 0)
+	logic.Update()
+	if !logic.IsGameOver() {
+		t.Error("game not over although new block is in solid board")
+	}
+	checkIntsEqual(t, spy.players, []int{0}, "game over observed")
+}
+
+func TestBlockPushedAboveTheBoardEndsTheGame(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{2, 2}, []Point{{0, 1}, {0, 1}})
+	spy := &spyGameOverObserver{}
+	logic.AddGameOverObserver(spy)
+	logic.StartNewGame(2)
+	logic.Update(
+		InputEvent{1, RightPressed},
+		InputEvent{1, DownPressed}, InputEvent{1, DownReleased},
+		InputEvent{1, DownPressed})
+	checkGame(t, logic, "1 dropped, 0 at 1's start position",
+		"0.",
+		".1",
+	)
+	logic.Update()
+	if !logic.IsGameOver() {
+		t.Error("game not over although new block was pushed out of the board")
+	}
+	checkIntsEqual(t, spy.players, []int{1}, "game over observed once")
+}
+
+func TestAfterGameOverUpdateDoesNothing(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 1}, []Point{{0, 0}})
+	logic.StartNewGame(1)
+	logic.Board().SetAt(1, 0, 0)
+	logic.Update(InputEvent{0, DownPressed})
+	logic.Update()
+	if !logic.IsGameOver() {
+		t.Fatal("game should be over")
+	}
+	logic.Update(InputEvent{0, RightPressed})
+	checkGame(t, logic, "nothing moves", "00.")
+}
+
+func TestNewGameIsNotOver(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 1}, []Point{{0, 0}})
+	logic.StartNewGame(1)
+	logic.Board().SetAt(1, 0, 0)
+	logic.Update(InputEvent{0, DownPressed})
+	logic.Update()
+	if !logic.IsGameOver() {
+		t.Fatal("game should be over")
+	}
+	logic.StartNewGame(1)
+	if logic.IsGameOver() {
+		t.Error("new game must not be over")
+	}
+}
+
+func TestHardDropLandsBlockInTheSameFrame(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{2, 4}, []Point{{0, 3}, {1, 3}})
+	logic.StartNewGame(2)
+	logic.Update(InputEvent{0, HardDrop}, InputEvent{0, RightPressed})
+	checkGame(t, logic, "block 0 landed and does not move anymore",
+		".1",
+		"..",
+		"..",
+		"0.",
+	)
+	logic.Update()
+	checkGame(t, logic, "block 0 solidified and reset",
+		"01",
+		"..",
+		"..",
+		"0.",
+	)
+}
+
+func TestHardDroppedBlockCanNotBeRotatedInTheSameFrame(t *testing.T) {
+	b := block(0, 0)
+	b.RotationDeltas = [][]Point{{{0, 1}}, {{0, -1}}}
+	logic := NewLogic(alwaysReturn(b))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{2, 3})
+	logic.SetBlockStartPositions(1, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, HardDrop}, InputEvent{0, RotateRight})
+	checkGame(t, logic, "block stays where it landed",
+		"..",
+		"..",
+		"0.",
+	)
+}
+
+func TestEmptyBlockFromFactoryCanBeHardDropped(t *testing.T) {
+	logic := NewLogic(blockSequence(block(0, 0), block()))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{2, 3})
+	logic.SetBlockStartPositions(1, []Point{{0, 2}})
+	logic.SetGhostsCollideWithOtherBlocks(true)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	logic.GhostBlocks()
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	checkGame(t, logic, "empty block landed and was replaced",
+		"0.",
+		"..",
+		"0.",
+	)
+}
+
+func TestHardDroppedBlockCompletesLines(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 3}, []Point{{0, 2}})
+	spy := &spyLineAnimation{}
+	logic.SetLineAnimation(spy)
+	logic.StartNewGame(1)
+	logic.Board().SetAt(1, 0, 0)
+	logic.Update(InputEvent{0, HardDrop})
+	if len(spy.lines) != 1 {
+		t.Fatal("animation not started, lines are", spy.lines)
+	}
+	checkIntsEqual(t, spy.lines[0], []int{0}, "lines")
+}
+
+func TestGroundedBlockCanBeMovedDuringLockDelay(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 2}, []Point{{0, 0}})
+	logic.SetLockDelay(2)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, RightPressed}, InputEvent{0, RightReleased})
+	checkGame(t, logic, "grounded block was moved",
+		"...",
+		".0.",
+	)
+	logic.Update()
+	logic.Update()
+	checkGame(t, logic, "block landed after lock delay and was reset",
+		"...",
+		"00.",
+	)
+}
+
+func TestHardDropIgnoresLockDelay(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 3}, []Point{{0, 2}})
+	logic.SetLockDelay(10)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	checkGame(t, logic, "block solidified right away",
+		"0.",
+		"..",
+		"0.",
+	)
+}
+
+func TestLockedBlockDoesNotDragOtherBlocksWhenLinesAreRemoved(t *testing.T) {
+	logic := NewLogic(blockSequence(block(0, 0, 1, 0, 0, 1), block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{2, 6})
+	logic.SetBlockStartPositions(2, []Point{{1, 3}, {1, 0}})
+	logic.SetLockDelay(10)
+	logic.StartNewGame(2)
+	logic.Board().SetAt(0, 0, Garbage)
+	logic.Update(InputEvent{0, HardDrop})
+	checkGame(t, logic, "block dropped onto grounded block",
+		"..",
+		"..",
+		"..",
+		"0.",
+		"00",
+		"G1",
+	)
+	logic.Update()
+	checkGame(t, logic, "grounded block stays in place",
+		"..",
+		"0.",
+		"00",
+		"..",
+		"0.",
+		"G1",
+	)
+}
+
+func TestMovingResetsLockDelayUpToTheLimit(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 2}, []Point{{0, 0}})
+	logic.SetLockDelay(2)
+	logic.SetLockDelayResetOnMove(true)
+	logic.SetMaxLockDelayResets(1)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, RightPressed}, InputEvent{0, RightReleased})
+	logic.Update(InputEvent{0, RightPressed}, InputEvent{0, RightReleased})
+	checkGame(t, logic, "first move resets the delay, block is not locked yet",
+		"...",
+		"..0",
+	)
+	logic.Update(InputEvent{0, LeftPressed}, InputEvent{0, LeftReleased})
+	logic.Update()
+	checkGame(t, logic, "second move does not reset the delay",
+		"...",
+		"0.0",
+	)
+}
+
+func TestBlockMovedOffTheGroundFallsAgainAfterLockDelay(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 3}, []Point{{0, 1}})
+	logic.SetLockDelay(2)
+	logic.StartNewGame(1)
+	logic.Board().SetAt(0, 0, 0)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, RightPressed}, InputEvent{0, RightReleased})
+	logic.Update()
+	logic.Update()
+	checkGame(t, logic, "block is not locked in the air",
+		"..",
+		".0",
+		"0.",
+	)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	checkGame(t, logic, "block falls again",
+		"..",
+		"..",
+		"00",
+	)
+}
+
+func TestGhostBlocksAreWhereBlocksWouldLand(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{2, 4}, []Point{{0, 3}, {0, 1}})
+	logic.StartNewGame(2)
+	checkBlocksEqual(t, logic.GhostBlocks(), block(0, 0), block(0, 0))
+	logic.SetGhostsCollideWithOtherBlocks(true)
+	checkBlocksEqual(t, logic.GhostBlocks(), block(0, 2), block(0, 0))
+}
+
+func TestLockScorerGetsLockEventsWithDropDistances(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 5}, []Point{{0, 4}})
+	spy := &spyLockScorer{}
+	logic.SetScorer(spy)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, HardDrop})
+	if len(spy.events) != 0 {
+		t.Fatal("lock event before block was solidified:", spy.events)
+	}
+	logic.Update()
+	if len(spy.events) != 1 {
+		t.Fatal("expected one lock event but have", spy.events)
+	}
+	e := spy.events[0]
+	checkInt(t, e.Player, 0, "player")
+	checkIntsEqual(t, e.Lines, []int{0}, "lines")
+	checkInt(t, e.SoftDropDistance, 2, "soft drop distance")
+	checkInt(t, e.HardDropDistance, 2, "hard drop distance")
+	if e.TSpin != NoTSpin {
+		t.Error("T-spin detected for single field block")
+	}
+}
+
+func TestDropScorerGetsDropDistancesPerFrame(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{2, 6}, []Point{{0, 5}, {1, 5}})
+	logic.SetInitialDownKeyDelay(1)
+	logic.SetShortDownKeyDelay(1)
+	spy := &spyDropScorer{}
+	logic.SetScorer(spy)
+	logic.StartNewGame(2)
+	logic.Update(InputEvent{0, DownPressed})
+	logic.Update()
+	logic.Update(InputEvent{0, DownReleased}, InputEvent{1, HardDrop})
+	logic.Update()
+	if len(spy.events) != 2 {
+		t.Fatal("expected drop events in 2 frames but have", spy.events)
+	}
+	checkDropEvents(t, spy.events[0], DropEvent{Player: 0, SoftDropDistance: 1})
+	checkDropEvents(t, spy.events[1],
+		DropEvent{Player: 0, SoftDropDistance: 1},
+		DropEvent{Player: 1, HardDropDistance: 5},
+	)
+}
+
+func checkDropEvents(t *testing.T, actual []DropEvent, expected ...DropEvent) {
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Error("expected drop events", expected, "but have", actual)
+	}
+}
+
+func TestTBlockRotatedIntoPlaceWithThreeCornersIsTSpin(t *testing.T) {
+	logic := createTSlotGame("X.X", "X..")
+	logic.Update(InputEvent{0, RotateRight}, InputEvent{0, RotateLeft})
+	checkTSpin(t, logic, FullTSpin)
+}
+
+func TestTSpinWithOnlyOneFrontCornerIsMini(t *testing.T) {
+	logic := createTSlotGame("..X", "X.X")
+	logic.Update(InputEvent{0, RotateRight}, InputEvent{0, RotateLeft})
+	checkTSpin(t, logic, MiniTSpin)
+}
+
+func TestTBlockNotRotatedIntoPlaceIsNoTSpin(t *testing.T) {
+	logic := createTSlotGame("X.X", "X..")
+	checkTSpin(t, logic, NoTSpin)
+}
+
+func TestMovingAfterRotationIsNoLongerRotatingIntoPlace(t *testing.T) {
+	logic := NewLogic(NewBlockFactory().CreateT)
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 5})
+	logic.SetBlockStartPositions(1, []Point{{1, 2}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, RotateRight})
+	if !logic.lastMoveWasRotation[0] {
+		t.Fatal("rotation not remembered")
+	}
+	logic.Update(InputEvent{0, RightPressed}, InputEvent{0, RightReleased})
+	if logic.lastMoveWasRotation[0] {
+		t.Error("rotation still remembered after moving right")
+	}
+	logic.Update(InputEvent{0, RotateLeft})
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	if logic.lastMoveWasRotation[0] {
+		t.Error("rotation still remembered after moving down")
+	}
+}
+
+// createTSlotGame starts a game on a 3x3 board with a T block pointing down
+// that spawns right in the slot between the given bottom and top rows.
+func createTSlotGame(bottom, top string) *Logic {
+	logic := NewLogic(NewBlockFactory().CreateT)
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{3, 3})
+	logic.SetBlockStartPositions(1, []Point{{1, 0}})
+	logic.SetScorer(&spyLockScorer{})
+	logic.StartNewGame(1)
+	for x := 0; x < 3; x++ {
+		if bottom[x] == 'X' {
+			logic.Board().SetAt(x, 0, 0)
+		}
+		if top[x] == 'X' {
+			logic.Board().SetAt(x, 2, 0)
+		}
+	}
+	return logic
+}
+
+func checkTSpin(t *testing.T, logic *Logic, expected TSpin) {
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	spy := logic.scorer.(*spyLockScorer)
+	if len(spy.events) != 1 {
+		t.Fatal("expected one lock event but have", spy.events)
+	}
+	if spy.events[0].TSpin != expected {
+		t.Error("expected T-spin", expected, "but was", spy.events[0].TSpin)
+	}
+}
+
+func TestHiddenRowsAreAddedOnTopOfTheBoard(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 3}})
+	logic.SetHiddenRows(2)
+	logic.StartNewGame(1)
+	if w, h := logic.Board().Size(); w != 2 || h != 4 {
+		t.Error("wrong board size", w, h)
+	}
+	checkInt(t, logic.Board().VisibleHeight(), 2, "visible height")
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	checkGame(t, logic, "block spawned in hidden rows and landed",
+		"0.",
+		"..",
+		"..",
+		"0.",
+	)
+}
+
+func TestTwoPlayersCanShareOneBlock(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 2}, []Point{{1, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0,
+		Commands: []Command{LeftPressed, RightPressed}})
+	logic.AddBlockControl(BlockControl{Player: 1, Block: 0,
+		Commands: []Command{DownPressed}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{1, LeftPressed})
+	checkGame(t, logic, "commands not given to the players are ignored",
+		".0.",
+		"...",
+	)
+	logic.Update(InputEvent{0, LeftReleased}, InputEvent{0, RightPressed},
+		InputEvent{1, DownPressed})
+	checkGame(t, logic, "both players moved the block",
+		"...",
+		"..0",
+	)
+}
+
+func TestRepeatedDownOfSharedBlockMovesItOnceForThePlayerHoldingIt(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 5}, []Point{{0, 4}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0})
+	logic.AddBlockControl(BlockControl{Player: 1, Block: 0})
+	spy := &spyDropScorer{}
+	logic.SetScorer(spy)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{1, DownPressed})
+	logic.Update()
+	checkGame(t, logic, "block moved down once per frame",
+		".",
+		".",
+		"0",
+		".",
+		".",
+	)
+	if len(spy.events) != 2 {
+		t.Fatal("expected drop events in 2 frames but have", spy.events)
+	}
+	checkDropEvents(t, spy.events[1], DropEvent{Player: 1, SoftDropDistance: 1})
+}
+
+func TestOnePlayerCanControlTwoBlocks(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{2, 2}, []Point{{0, 1}, {1, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 1})
+	logic.StartNewGame(2)
+	logic.Update(InputEvent{1, HardDrop})
+	checkGame(t, logic, "player 1 controls no block",
+		"01",
+		"..",
+	)
+	logic.Update(InputEvent{0, HardDrop})
+	checkGame(t, logic, "player 0 dropped both blocks",
+		"..",
+		"01",
+	)
+}
+
+func TestLinesOfSharedBlockAreScoredForAllItsPlayers(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 2}, []Point{{0, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0,
+		Commands: []Command{RotateLeft, RotateRight}})
+	logic.AddBlockControl(BlockControl{Player: 2, Block: 0,
+		Commands: []Command{HardDrop}})
+	scorer := &spyDropScorer{}
+	logic.SetScorer(scorer)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{2, HardDrop})
+	checkDropEvents(t, scorer.events[0], DropEvent{Player: 2, HardDropDistance: 1})
+	logic.Update()
+	if len(scorer.lines) != 3 {
+		t.Fatal("expected lines for 3 players but have", scorer.lines)
+	}
+	checkIntsEqual(t, scorer.lines[0], []int{0}, "lines of player 0")
+	checkIntsEqual(t, scorer.lines[1], nil, "lines of player 1")
+	checkIntsEqual(t, scorer.lines[2], []int{0}, "lines of player 2")
+}
+
+func TestBlocksStartSpreadOverBoardWithoutStartPositions(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(3, BoardSize{6, 3})
+	logic.StartNewGame(3)
+	checkGame(t, logic, "blocks centered in their parts of the board",
+		"......",
+		".0.1.2",
+		"......",
+	)
+}
+
+func TestEightPlayersCanPlayOnWideBoard(t *testing.T) {
+	logic := NewLogic(alwaysReturn(block(0, 0)))
+	logic.SetBoardSizeForPlayerCount(8, BoardSize{8, 2})
+	scorer := NewTeamScorer()
+	logic.SetScorer(scorer)
+	logic.StartNewGame(8)
+	var drops []InputEvent
+	for player := 0; player < 8; player++ {
+		drops = append(drops, InputEvent{player, DownPressed})
+	}
+	logic.Update(drops...)
+	logic.Update()
+	checkInt(t, scorer.ScoreForTeam(0), lineScore(1), "score")
+	checkInt(t, scorer.ScoreForPlayer(7), lineScore(1), "score of player 7")
+}
+
+func TestPauseEventTogglesPausedState(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{3, 1}, []Point{{0, 0}, {2, 0}})
+	logic.StartNewGame(2)
+	if logic.IsPaused() {
+		t.Fatal("new game is paused")
+	}
+	logic.Update(InputEvent{1, Pause})
+	if !logic.IsPaused() {
+		t.Fatal("game not paused")
+	}
+	logic.Update(InputEvent{0, Pause})
+	if logic.IsPaused() {
+		t.Error("game not resumed")
+	}
+}
+
+func TestSimultaneousPauseEventsPauseOnlyOnce(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{3, 1}, []Point{{0, 0}, {2, 0}})
+	logic.StartNewGame(2)
+	logic.Update(InputEvent{0, Pause}, InputEvent{1, Pause})
+	if !logic.IsPaused() {
+		t.Fatal("game not paused")
+	}
+	logic.Update(InputEvent{0, Pause}, InputEvent{1, Pause})
+	if logic.IsPaused() {
+		t.Error("game not resumed")
+	}
+}
+
+func TestNegativePlayerCanNotPause(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 1}, []Point{{0, 0}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{-1, Pause})
+	if logic.IsPaused() {
+		t.Error("game paused by negative player")
+	}
+}
+
+func TestWhilePausedNothingMoves(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.SetInitialLeftRightKeyDelay(0)
+	logic.SetShortLeftRightKeyDelay(0)
+	timer := &spyDropTimer{isTimeForDrop: true}
+	logic.SetDropTimer(timer)
+	animation := &spyLineAnimation{running: true}
+	logic.SetLineAnimation(animation)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, Pause}, InputEvent{0, RightPressed})
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, RotateLeft})
+	checkGame(t, logic, "nothing moved while paused",
+		"0..",
+		"...",
+		"...",
+	)
+	checkInt(t, timer.updated, 0, "drop timer updates while paused")
+	checkInt(t, animation.updated, 0, "animation updates while paused")
+}
+
+func TestKeyRepeatIsFrozenWhilePaused(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{5, 1}, []Point{{0, 0}})
+	logic.SetInitialLeftRightKeyDelay(1)
+	logic.SetShortLeftRightKeyDelay(1)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, RightPressed})
+	logic.Update(InputEvent{0, Pause})
+	logic.Update()
+	logic.Update()
+	checkGame(t, logic, "not repeated while paused", ".0...")
+	logic.Update(InputEvent{0, Pause})
+	checkGame(t, logic, "repeat timer continues after resume", ".0...")
+	logic.Update()
+	checkGame(t, logic, "repeated after resume", "..0..")
+}
+
+func TestKeysReleasedWhilePausedAreReleasedAfterResume(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{5, 1}, []Point{{0, 0}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, RightPressed})
+	logic.Update(InputEvent{0, Pause}, InputEvent{0, RightReleased})
+	logic.Update(InputEvent{0, Pause})
+	logic.Update()
+	logic.Update()
+	checkGame(t, logic, "no repeat after release", ".0...")
+}
+
+func TestOnlyHostMayPauseIfPolicySaysSo(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{3, 1}, []Point{{0, 0}, {2, 0}})
+	logic.SetPausePolicy(OnlyHostMayPause)
+	logic.StartNewGame(2)
+	logic.Update(InputEvent{1, Pause})
+	if logic.IsPaused() {
+		t.Fatal("non-host paused the game")
+	}
+	logic.Update(InputEvent{Host, Pause})
+	if !logic.IsPaused() {
+		t.Fatal("host could not pause")
+	}
+	logic.Update(InputEvent{1, Pause})
+	if !logic.IsPaused() {
+		t.Error("non-host resumed the game")
+	}
+}
+
+func TestNewGameIsNotPaused(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 1}, []Point{{0, 0}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, Pause})
+	logic.StartNewGame(1)
+	if logic.IsPaused() {
+		t.Error("new game is paused")
+	}
+}
+
+func TestInitiallyNoBlocksAreHeld(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{4, 2}, []Point{{0, 0}, {2, 0}})
+	logic.StartNewGame(2)
+	checkBlocksEqual(t, logic.HeldBlocks(), Block{}, Block{})
+}
+
+func TestHoldingPutsBlockAsideAndUsesPreview(t *testing.T) {
+	logic := NewLogic(blockSequence(block(0, 0), block(0, 0, 1, 0)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 2})
+	logic.SetBlockStartPositions(1, []Point{{2, 1}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, LeftPressed}, InputEvent{0, Hold})
+	checkBlocksEqual(t, logic.HeldBlocks(), block(0, 0))
+	checkGame(t, logic, "preview block placed at start",
+		".00.",
+		"....",
+	)
+	checkBlocksEqual(t, logic.PreviewBlocks(), block(0, 0))
+}
+
+func TestHoldingIsOnlyPossibleOnceUntilBlockLands(t *testing.T) {
+	logic := NewLogic(blockSequence(block(0, 0), block(0, 0, 1, 0), block(0, 0, 0, 1)))
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{4, 2})
+	logic.SetBlockStartPositions(1, []Point{{2, 1}})
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, Hold})
+	logic.Update(InputEvent{0, Hold})
+	checkBlocksEqual(t, logic.HeldBlocks(), block(0, 0))
+	checkGame(t, logic, "second hold is ignored",
+		".00.",
+		"....",
+	)
+
+	logic.Update(InputEvent{0, DownPressed}, InputEvent{0, DownReleased})
+	logic.Update(InputEvent{0, DownPressed})
+	logic.Update(InputEvent{0, Hold})
+	checkBlocksEqual(t, logic.HeldBlocks(), block(0, 0, 0, 1))
+	checkGame(t, logic, "held block swapped back in at start",
+		"..0.",
+		".00.",
+	)
+}
+
+func blockSequence(blocks ...Block) BlockFactory {
+	next := -1
+	return func() Block {
+		next = (next + 1) % len(blocks)
+		return blocks[next].Copy()
+	}
+}
+
+func TestPreviewQueueHoldsConfiguredNumberOfBlocks(t *testing.T) {
+	l := NewLogic(increasingYBlocks(1))
+	l.SetPreviewCount(3)
+	l.SetBoardSizeForPlayerCount(1, BoardSize{20, 20})
+	l.SetBlockStartPositions(1, []Point{{10, 10}})
+	l.StartNewGame(1)
+	checkBlocksEqual(t, l.PreviewQueue(0), block(0, 2), block(0, 3), block(0, 4))
+	checkBlocksEqual(t, l.PreviewBlocks(), block(0, 2))
+}
+
+func TestPreviewQueuesAreFilledForAllPlayersInTurn(t *testing.T) {
+	l := NewLogic(increasingYBlocks(1))
+	l.SetPreviewCount(2)
+	l.SetBoardSizeForPlayerCount(2, BoardSize{4, 20})
+	l.SetBlockStartPositions(2, []Point{{0, 0}, {2, 0}})
+	l.StartNewGame(2)
+	checkBlocksEqual(t, l.PreviewQueue(0), block(0, 3), block(0, 5))
+	checkBlocksEqual(t, l.PreviewQueue(1), block(0, 4), block(0, 6))
+}
+
+func TestLandedBlockIsReplacedByFirstInPreviewQueue(t *testing.T) {
+	l := NewLogic(increasingYBlocks(0))
+	l.SetPreviewCount(2)
+	l.SetBoardSizeForPlayerCount(1, BoardSize{4, 20})
+	l.SetBlockStartPositions(1, []Point{{0, 0}})
+	l.StartNewGame(1)
+	l.Update(InputEvent{0, HardDrop})
+	l.Update()
+	checkBlocksEqual(t, l.Blocks(), block(0, 1))
+	checkBlocksEqual(t, l.PreviewQueue(0), block(0, 2), block(0, 3))
+}
+
+func TestSeededGamesWithSameInputsAreIdentical(t *testing.T) {
+	a, aScores := createSeededTwoPlayerGame(42)
+	b, bScores := createSeededTwoPlayerGame(42)
+	for frame := 0; frame < 500; frame++ {
+		events := scriptedInputs(frame)
+		a.Update(events...)
+		b.Update(events...)
+		if gameToString(a) != gameToString(b) {
+			t.Fatal("games differ in frame", frame, gameToString(a), gameToString(b))
+		}
+		if fmt.Sprint(a.PreviewBlocks()) != fmt.Sprint(b.PreviewBlocks()) {
+			t.Fatal("previews differ in frame", frame)
+		}
+		for team := 0; team < 2; team++ {
+			if aScores.ScoreForTeam(team) != bScores.ScoreForTeam(team) {
+				t.Fatal("scores differ in frame", frame)
+			}
+		}
+	}
+}
+
+func TestGamesWithDifferentSeedsDiffer(t *testing.T) {
+	a, _ := createSeededTwoPlayerGame(1)
+	b, _ := createSeededTwoPlayerGame(2)
+	same := true
+	for frame := 0; frame < 100; frame++ {
+		a.Update(scriptedInputs(frame)...)
+		b.Update(scriptedInputs(frame)...)
+		same = same && fmt.Sprint(a.PreviewBlocks()) == fmt.Sprint(b.PreviewBlocks())
+	}
+	if same {
+		t.Error("different seeds created the same blocks")
+	}
+}
+
+func TestNewGameUsesCurrentSeed(t *testing.T) {
+	logic, _ := createSeededTwoPlayerGame(1)
+	first := fmt.Sprint(logic.Blocks(), logic.PreviewBlocks())
+	logic.SetSeed(1)
+	logic.StartNewGame(2)
+	if again := fmt.Sprint(logic.Blocks(), logic.PreviewBlocks()); again != first {
+		t.Error("restarting with the same seed created different blocks")
+	}
+	checkInt(t, int(logic.Seed()), 1, "seed")
+}
+
+func createSeededTwoPlayerGame(seed int64) (*Logic, *TeamScorer) {
+	logic := NewSeededLogic(NewSevenBagRandomizer)
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{10, 18})
+	logic.SetBlockStartPositions(2, []Point{{7, 16}, {2, 16}})
+	logic.SetDropTimer(&spyDropTimer{isTimeForDrop: true})
+	scorer := NewTeamScorer()
+	scorer.AssignPlayerToTeam(1, 1)
+	logic.SetScorer(scorer)
+	logic.SetSeed(seed)
+	logic.StartNewGame(2)
+	return logic, scorer
+}
+
+// scriptedInputs creates some input events for the given frame, always the
+// same for the same frame.
+func scriptedInputs(frame int) []InputEvent {
+	commands := []Command{
+		LeftPressed, RotateRight, RightPressed, LeftReleased, DownPressed,
+		RightReleased, RotateLeft, DownReleased, HardDrop, Hold,
+	}
+	return []InputEvent{
+		{frame % 2, commands[frame%len(commands)]},
+		{(frame / 3) % 2, commands[(frame/2)%len(commands)]},
+	}
+}
+
 // test helpers start here /////////////////////////////////////////////////////
 
 func createSingleBlockGame(players int, size BoardSize, starts []Point) *Logic {
@@ -899,7 +1762,9 @@ func make2DByteField(w, h int) [][]byte {
 func addBoardFields(fields [][]byte, board Board, w, h int) {
 	for x := 0; x < w; x++ {
 		for y := 0; y < h; y++ {
-			if f := board.At(x, y); f != NoPlayer {
+			if f := board.At(x, y); f == Garbage {
+				fields[h-1-y][x] = 'G'
+			} else if f != NoPlayer {
 				fields[h-1-y][x] = byte(f) + '0'
 			} else {
 				fields[h-1-y][x] = '.'
@@ -1000,6 +1865,24 @@ type spyScorer struct {
 
 func (s *spyScorer) LinesRemoved(lines [][]int) { s.lines = lines }
 
+type spyLockScorer struct {
+	spyScorer
+	events []LockEvent
+}
+
+func (s *spyLockScorer) BlocksLocked(events []LockEvent) {
+	s.events = append(s.events, events...)
+}
+
+type spyDropScorer struct {
+	spyScorer
+	events [][]DropEvent
+}
+
+func (s *spyDropScorer) BlocksDropped(events []DropEvent) {
+	s.events = append(s.events, events)
+}
+
 type spySoundPlayer struct {
 	spyCollisionObserver
 	spyBlockMoveObserver
@@ -1009,3 +1892,11 @@ type spySoundPlayer struct {
 func (spy *spySoundPlayer) PlaySounds() {
 	spy.played++
 }
+
+type spyGameOverObserver struct {
+	players []int
+}
+
+func (spy *spyGameOverObserver) GameOver(player int) {
+	spy.players = append(spy.players, player)
+}
@@ -9,6 +9,7 @@ type physics struct {
 	blocks                  []Block
 	collisionObservers      []BlockCollisionObserver
 	moveObservers           []BlockMoveObserver
+	pushObservers           []BlockPushObserver
 	board                   board
 }
 
@@ -19,11 +20,18 @@ type BoardSize struct {
 type BlockCount int
 
 func newPhysics(size BoardSize, blocks BlockCount) *physics {
+	return newPhysicsWithHiddenRows(size, 0, blocks)
+}
+
+// newPhysicsWithHiddenRows creates a board with the given number of hidden rows
+// on top of the visible size. Blocks collide and lock in the hidden rows just
+// like in the visible ones.
+func newPhysicsWithHiddenRows(size BoardSize, hidden int, blocks BlockCount) *physics {
 	return &physics{
 		boardWidth:  size.Width,
-		boardHeight: size.Height,
+		boardHeight: size.Height + hidden,
 		blocks:      make([]Block, blocks),
-		board:       newBoard(size.Width, size.Height),
+		board:       newBoardWithHiddenRows(size.Width, size.Height, hidden),
 	}
 }
 
@@ -35,6 +43,10 @@ func (p *physics) AddBlockMoveObserver(o BlockMoveObserver) {
 	p.moveObservers = append(p.moveObservers, o)
 }
 
+func (p *physics) AddBlockPushObserver(o BlockPushObserver) {
+	p.pushObservers = append(p.pushObservers, o)
+}
+
 func (p *physics) Board() Board {
 	return p.board
 }
@@ -80,6 +92,15 @@ func (p *physics) isInWall(block int) bool {
 	return false
 }
 
+func (p *physics) isAboveTop(block int) bool {
+	for _, point := range p.blocks[block].Points {
+		if point.Y >= p.boardHeight {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *physics) isInSolidPartOfBoard(block int) bool {
 	for _, point := range p.blocks[block].Points {
 		if p.board.isBlocked(point.X, point.Y) {
@@ -127,17 +148,93 @@ func (p *physics) notifyOfHorizontalMove(block int) {
 	}
 }
 
-func (p *physics) MoveDown(block int) {
+// MoveDown moves the block down by one and returns true if that was possible.
+func (p *physics) MoveDown(block int) bool {
 	p.blocks[block].MoveBy(0, -1)
 	if p.isInGround(block) || p.isInSolidPartOfBoard(block) {
 		p.blocks[block].MoveBy(0, 1)
 		p.notifyOfGroundHit(block)
+		return false
 	} else if p.isInOtherBlock(block) {
 		p.blocks[block].MoveBy(0, 1)
 		p.notifyOfBlockHit(block)
-	} else {
+		return false
+	}
+	p.notifyOfDownMove(block)
+	return true
+}
+
+// HardDrop moves the block down as far as possible and then notifies of a
+// ground hit, even if the block landed on another block instead of the ground
+// or the board. It returns the number of fields the block was moved.
+func (p *physics) HardDrop(block int) (distance int) {
+	for p.canMoveDown(block) {
+		p.blocks[block].MoveBy(0, -1)
+		distance++
+	}
+	if distance > 0 {
 		p.notifyOfDownMove(block)
 	}
+	p.notifyOfGroundHit(block)
+	return
+}
+
+// canMoveDown is false for a block without points so that hard dropping it
+// ends.
+func (p *physics) canMoveDown(block int) bool {
+	if len(p.blocks[block].Points) == 0 {
+		return false
+	}
+	p.blocks[block].MoveBy(0, -1)
+	defer p.blocks[block].MoveBy(0, 1)
+	return !p.isInGround(block) && !p.isInSolidPartOfBoard(block) &&
+		!p.isInOtherBlock(block)
+}
+
+// Ghost returns a copy of the block at the position where it would land if it
+// was hard dropped. If otherBlocks is true, it lands on top of the other blocks
+// as well. Neither the block is moved nor are any observers notified.
+func (p *physics) Ghost(block int, otherBlocks bool) Block {
+	ghost := p.blocks[block].Copy()
+	for p.ghostCanMoveDown(ghost, block, otherBlocks) {
+		ghost.MoveBy(0, -1)
+	}
+	return ghost
+}
+
+func (p *physics) ghostCanMoveDown(ghost Block, block int, otherBlocks bool) bool {
+	if len(ghost.Points) == 0 {
+		return false
+	}
+	for _, point := range ghost.Points {
+		below := Point{point.X, point.Y - 1}
+		if below.Y < 0 || p.board.isBlocked(below.X, below.Y) {
+			return false
+		}
+		if otherBlocks && p.isInOtherBlockAt(below, block) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *physics) isInOtherBlockAt(point Point, block int) bool {
+	for other := range p.blocks {
+		if other != block {
+			for _, q := range p.blocks[other].Points {
+				if q == point {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isSolidAt returns true for the walls, the ground and the solid part of the
+// board. The area above the board is not solid.
+func (p *physics) isSolidAt(x, y int) bool {
+	return x < 0 || x >= p.boardWidth || y < 0 || p.board.isBlocked(x, y)
 }
 
 func (p *physics) isInGround(block int) bool {
@@ -187,16 +284,74 @@ func sortDescending(ints []int) {
 
 func (p *physics) removeLine(line int) {
 	for y := line; y < p.boardHeight-1; y++ {
-		copy(p.board[y], p.board[y+1])
+		copy(p.board.fields[y], p.board.fields[y+1])
 	}
 	p.clearTopRow()
 	p.resolveLineRemovalCollisions()
 }
 
+// InsertGarbage pushes the board up by the given number of rows and fills the
+// bottom rows with Garbage, except for the hole columns. Blocks that collide
+// with the board are pushed up as well, just like blocks are dragged down when
+// lines are removed. It returns true if solid spots were pushed out over the
+// top of the board. Blocks may be pushed above the top of the board.
+func (p *physics) InsertGarbage(rows int, holeColumns []int) (overflow bool) {
+	var pushed []int
+	for i := 0; i < rows; i++ {
+		if p.insertGarbageRow(holeColumns) {
+			overflow = true
+		}
+		for _, block := range p.resolveGarbageCollisions() {
+			if !contains(pushed, block) {
+				pushed = append(pushed, block)
+			}
+		}
+	}
+	sort.Ints(pushed)
+	for _, block := range pushed {
+		p.notifyOfPushUp(block)
+	}
+	return
+}
+
+func (p *physics) insertGarbageRow(holeColumns []int) (overflow bool) {
+	top := p.boardHeight - 1
+	for x := 0; x < p.boardWidth; x++ {
+		overflow = overflow || p.board.fields[top][x] != NoPlayer
+	}
+	for y := top; y > 0; y-- {
+		copy(p.board.fields[y], p.board.fields[y-1])
+	}
+	for x := 0; x < p.boardWidth; x++ {
+		p.board.fields[0][x] = Garbage
+	}
+	for _, x := range holeColumns {
+		if 0 <= x && x < p.boardWidth {
+			p.board.fields[0][x] = NoPlayer
+		}
+	}
+	return
+}
+
+func (p *physics) resolveGarbageCollisions() (pushed []int) {
+	collided, ok := p.findGroundAndBoardHits(1)
+	moreCollisions := true
+	for moreCollisions {
+		moreCollisions, collided, ok = p.findMoreCollisions(collided, ok, 1)
+	}
+	return collided
+}
+
+func (p *physics) notifyOfPushUp(block int) {
+	for _, o := range p.pushObservers {
+		o.BlockPushedUpByGarbage(block)
+	}
+}
+
 func (p *physics) clearTopRow() {
 	top := p.boardHeight - 1
 	for x := 0; x < p.boardWidth; x++ {
-		p.board[top][x] = NoPlayer
+		p.board.fields[top][x] = NoPlayer
 	}
 }
 
@@ -279,25 +434,47 @@ func (p *physics) notifyOfDropCollisions(blocks []int) {
 }
 
 func (p *physics) RotateRight(block int) {
+	kicks := p.blocks[block].rightKicks()
 	p.blocks[block].RotateRight()
-	p.handleRotationCollision(block, p.blocks[block].RotateLeft)
+	p.handleRotationCollision(block, kicks, p.blocks[block].RotateLeft)
 }
 
 func (p *physics) RotateLeft(block int) {
+	kicks := p.blocks[block].leftKicks()
 	p.blocks[block].RotateLeft()
-	p.handleRotationCollision(block, p.blocks[block].RotateRight)
+	p.handleRotationCollision(block, kicks, p.blocks[block].RotateRight)
 }
 
-func (p *physics) handleRotationCollision(block int, reset func()) {
-	if p.isInWall(block) || p.isInGround(block) ||
-		p.isInSolidPartOfBoard(block) || p.isInOtherBlock(block) {
+func (p *physics) handleRotationCollision(block int, kicks []Point, reset func()) {
+	if p.fitsWithKicks(block, kicks) {
+		p.notifyOfRotation(block)
+	} else {
 		reset()
 		p.notifyOfRotationHit(block)
-	} else {
-		p.notifyOfRotation(block)
 	}
 }
 
+// fitsWithKicks moves the block by the first of the kick offsets that makes it
+// fit. Without kicks, only the current position is tried.
+func (p *physics) fitsWithKicks(block int, kicks []Point) bool {
+	if len(kicks) == 0 {
+		kicks = []Point{{0, 0}}
+	}
+	for _, kick := range kicks {
+		p.blocks[block].MoveBy(kick.X, kick.Y)
+		if !p.isBlockedAnywhere(block) {
+			return true
+		}
+		p.blocks[block].MoveBy(-kick.X, -kick.Y)
+	}
+	return false
+}
+
+func (p *physics) isBlockedAnywhere(block int) bool {
+	return p.isInWall(block) || p.isInGround(block) ||
+		p.isInSolidPartOfBoard(block) || p.isInOtherBlock(block)
+}
+
 func (p *physics) notifyOfRotationHit(block int) {
 	for _, o := range p.collisionObservers {
 		o.BlockCouldNotRotate(block)
@@ -289,6 +289,17 @@ func blockBoardWith(block int, at []Point) {
 	p.SetBlock(block, Block{})
 }
 
+func TestMoveDownReportsWhetherBlockMoved(t *testing.T) {
+	p = newPhysics(BoardSize{1, 2}, BlockCount(1))
+	p.SetBlock(0, Block{Points: []Point{{0, 1}}})
+	if !p.MoveDown(0) {
+		t.Error("move down not reported")
+	}
+	if p.MoveDown(0) {
+		t.Error("move into ground reported")
+	}
+}
+
 func TestBlockDoesNotMoveDownIntoSolidBoard(t *testing.T) {
 	p = newPhysics(BoardSize{3, 3}, BlockCount(2))
 	o := &spyCollisionObserver{}
@@ -638,6 +649,204 @@ func TestOtherBlockBlockingRotationIsObserved(t *testing.T) {
 
 // auxiliary test variables and functions start here
 
+func TestHardDropMovesBlockDownUntilItLands(t *testing.T) {
+	p = newPhysics(BoardSize{3, 6}, BlockCount(2))
+	p.SetBlock(0, T_at(0, 4))
+	blockBoardWith(1, []Point{{1, 0}})
+	if distance := p.HardDrop(0); distance != 3 {
+		t.Error("expected drop distance 3 but was", distance)
+	}
+	checkBlocks(t, "landed on board",
+		"...",
+		"...",
+		"...",
+		"000",
+		".0.",
+		"...")
+}
+
+func TestHardDropOntoOtherBlockIsObservedAsGroundHit(t *testing.T) {
+	p = newPhysics(BoardSize{3, 7}, BlockCount(2))
+	spy := &spyCollisionObserver{}
+	p.AddCollisionObserver(spy)
+	moves := &spyBlockMoveObserver{}
+	p.AddBlockMoveObserver(moves)
+	p.SetBlock(0, I_at(1, 0))
+	p.SetBlock(1, T_at(0, 5))
+	p.HardDrop(1)
+	checkBlocks(t, "landed on block 0",
+		"111",
+		".1.",
+		".0.",
+		".0.",
+		".0.",
+		".0.")
+	checkIntsEqual(t, spy.groundHits, []int{1}, "ground hit")
+	checkIntsEqual(t, spy.blockHits, nil, "no block hit")
+	if moves.log != "1 down " {
+		t.Error("down move not observed once, log was:", moves.log)
+	}
+}
+
+func TestBlockedRotationTriesKicks(t *testing.T) {
+	p = newPhysics(BoardSize{3, 1}, BlockCount(1))
+	spy := &spyBlockMoveObserver{}
+	p.AddBlockMoveObserver(spy)
+	p.SetBlock(0, kickingPoint(0, 0, -1, []Point{{0, 0}, {1, 0}}))
+	p.RotateRight(0)
+	checkBlocks(t, "kicked right out of the wall", "0..")
+	if spy.log != "0 rotated " {
+		t.Error("rotation not observed, log was:", spy.log)
+	}
+}
+
+func TestRotationFailsIfAllKicksAreBlocked(t *testing.T) {
+	p = newPhysics(BoardSize{3, 1}, BlockCount(2))
+	spy := &spyCollisionObserver{}
+	p.AddCollisionObserver(spy)
+	p.SetBlock(0, kickingPoint(1, 0, -1, []Point{{0, 0}, {-1, 0}}))
+	blockBoardWith(1, []Point{{0, 0}})
+	p.RotateRight(0)
+	checkBlocks(t, "not rotated", ".0.")
+	checkIntsEqual(t, spy.rotationHits, []int{0}, "rotation hit")
+}
+
+func TestRotatingLeftUsesNegatedKicks(t *testing.T) {
+	p = newPhysics(BoardSize{3, 1}, BlockCount(1))
+	p.SetBlock(0, kickingPoint(2, 0, -1, []Point{{0, 0}, {1, 0}}))
+	p.RotateLeft(0)
+	checkBlocks(t, "kicked left out of the wall", "..0")
+}
+
+func TestGhostIsBlockAtLandingPosition(t *testing.T) {
+	p = newPhysics(BoardSize{3, 6}, BlockCount(2))
+	spy := &spyBlockMoveObserver{}
+	p.AddBlockMoveObserver(spy)
+	p.SetBlock(0, T_at(0, 4))
+	blockBoardWith(1, []Point{{1, 0}})
+	ghost := p.Ghost(0, false)
+	checkBlocksEqual(t, []Block{ghost}, T_at(0, 1))
+	checkBlocks(t, "block did not move",
+		"000",
+		".0.",
+		"...",
+		"...",
+		"...",
+		"...")
+	if spy.log != "" {
+		t.Error("ghost computation was observed:", spy.log)
+	}
+}
+
+func TestGhostCanIgnoreOtherBlocks(t *testing.T) {
+	p = newPhysics(BoardSize{1, 5}, BlockCount(2))
+	p.SetBlock(0, Block{Points: []Point{{0, 4}}})
+	p.SetBlock(1, Block{Points: []Point{{0, 1}}})
+	checkBlocksEqual(t, []Block{p.Ghost(0, false), p.Ghost(0, true)},
+		Block{Points: []Point{{0, 0}}},
+		Block{Points: []Point{{0, 2}}},
+	)
+}
+
+func TestBlockWithoutPointsLandsRightAway(t *testing.T) {
+	p = newPhysics(BoardSize{2, 3}, BlockCount(1))
+	spy := &spyCollisionObserver{}
+	p.AddCollisionObserver(spy)
+	if distance := p.HardDrop(0); distance != 0 {
+		t.Error("expected drop distance 0 but was", distance)
+	}
+	checkIntsEqual(t, spy.groundHits, []int{0}, "ground hit")
+	checkBlocksEqual(t, []Block{p.Ghost(0, true)}, Block{})
+}
+
+func TestGarbageIsInsertedBelowBoard(t *testing.T) {
+	p = newPhysics(BoardSize{3, 4}, BlockCount(2))
+	blockBoardWith(1, []Point{{0, 0}, {2, 1}})
+	if p.InsertGarbage(2, []int{1}) {
+		t.Error("overflow reported")
+	}
+	checkBoard(t, "pushed up",
+		"..1",
+		"1..",
+		"G.G",
+		"G.G")
+}
+
+func TestGarbagePushingSolidFieldsOverTopIsReported(t *testing.T) {
+	p = newPhysics(BoardSize{2, 2}, BlockCount(2))
+	blockBoardWith(1, []Point{{1, 1}})
+	if !p.InsertGarbage(1, []int{0}) {
+		t.Error("overflow not reported")
+	}
+	checkBoard(t, "top row lost",
+		"..",
+		".G")
+}
+
+func TestGarbageRowsCanHaveSeveralHoles(t *testing.T) {
+	p = newPhysics(BoardSize{4, 2}, BlockCount(1))
+	p.InsertGarbage(1, []int{0, 2})
+	checkBoard(t, "two holes",
+		"....",
+		".G.G")
+}
+
+func TestBlocksAreNotPushedIfGarbageDoesNotReachThem(t *testing.T) {
+	p = newPhysics(BoardSize{3, 4}, BlockCount(1))
+	spy := &spyBlockPushObserver{}
+	p.AddBlockPushObserver(spy)
+	p.SetBlock(0, T_at(0, 2))
+	p.InsertGarbage(2, []int{1})
+	checkBlocks(t, "not pushed",
+		"000",
+		".0.",
+		"...",
+		"...")
+	checkIntsEqual(t, spy.pushed, nil, "pushed")
+}
+
+func TestGarbagePushesCollidingBlocksUpInChain(t *testing.T) {
+	p = newPhysics(BoardSize{3, 8}, BlockCount(2))
+	spy := &spyBlockPushObserver{}
+	p.AddBlockPushObserver(spy)
+	p.SetBlock(0, T_at(0, 4))
+	p.SetBlock(1, I_at(0, 0))
+	p.InsertGarbage(2, []int{2})
+	checkBlocks(t, "I pushed out of garbage, T pushed out of I",
+		"...",
+		"000",
+		"10.",
+		"1..",
+		"1..",
+		"1..",
+		"...",
+		"...")
+	checkIntsEqual(t, spy.pushed, []int{0, 1}, "pushed")
+}
+
+func TestBlocksLockCompletelyInHiddenRows(t *testing.T) {
+	p = newPhysicsWithHiddenRows(BoardSize{3, 2}, 2, BlockCount(1))
+	p.SetBlock(0, T_at(0, 2))
+	p.CopyBlockToBoard(0)
+	p.SetBlock(0, Block{})
+	checkBoard(t, "all pieces locked",
+		"000",
+		".0.",
+		"...",
+		"...")
+	p.SetBlock(0, I_at(1, 4))
+	p.MoveDown(0)
+	p.MoveDown(0)
+	checkBlocks(t, "I stopped on hidden pieces",
+		"...",
+		"...",
+		"...",
+		"...")
+	if y := p.Blocks()[0].Points[0].Y; y != 4 {
+		t.Error("I moved into hidden pieces to", y)
+	}
+}
+
 var p *physics
 
 func checkBlocks(t *testing.T, msg string, blockMap ...string) {
@@ -691,6 +900,14 @@ func I_at(x, y int) Block {
 		{x, y + 3}}}
 }
 
+type spyBlockPushObserver struct {
+	pushed []int
+}
+
+func (spy *spyBlockPushObserver) BlockPushedUpByGarbage(block int) {
+	spy.pushed = append(spy.pushed, block)
+}
+
 type spyCollisionObserver struct {
 	horizontalHits []int
 	blockHits      []int
@@ -735,6 +952,8 @@ func checkBoard(t *testing.T, msg string, fields ...string) {
 			field := board.At(x, h-1-y)
 			if field == NoPlayer {
 				actual += "."
+			} else if field == Garbage {
+				actual += "G"
 			} else {
 				actual += fmt.Sprint(field)
 			}
@@ -779,3 +998,12 @@ func (spy *spyBlockMoveObserver) BlockMovedDown(block int) {
 func (spy *spyBlockMoveObserver) BlockRotated(block int) {
 	spy.log += fmt.Sprintf("%v rotated ", block)
 }
+
+// kickingPoint creates a single point block that moves by dx when rotated right
+// and has the given kicks.
+func kickingPoint(x, y, dx int, kicks []Point) Block {
+	return Block{
+		Points:         []Point{{x, y}},
+		RotationDeltas: [][]Point{[]Point{{dx, 0}}},
+		Kicks:          [][]Point{kicks}}
+}
@@ -0,0 +1,239 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AddPlayer lets a new player join the running game and returns the new
+// player's index. The player gets a new block, the board is resized to the
+// BoardSize set for the new number of blocks. The solid part of the board stays
+// at the bottom and is centered horizontally, the blocks move with it. If there
+// are BlockControls, the new player is given control of the new block.
+func (l *Logic) AddPlayer() (player int, err error) {
+	if l.physics == nil {
+		return 0, errors.New("no game is running")
+	}
+	if err := l.checkPlayerCountChange(l.playerCount + 1); err != nil {
+		return 0, err
+	}
+	block := l.playerCount
+	player = l.players()
+	l.hasDroppedThisFrame = append(l.hasDroppedThisFrame, false)
+	l.heldBlocks = append(l.heldBlocks, Block{})
+	l.unplacedBlocks = append(l.unplacedBlocks, Block{})
+	l.hasHeld = append(l.hasHeld, false)
+	l.grounded = append(l.grounded, false)
+	l.lockTimers = append(l.lockTimers, 0)
+	l.lockResets = append(l.lockResets, 0)
+	l.lastMoveWasRotation = append(l.lastMoveWasRotation, false)
+	l.softDrops = append(l.softDrops, 0)
+	l.hardDrops = append(l.hardDrops, 0)
+	queue := make([]Block, l.previewQueueLength())
+	for i := range queue {
+		queue[i] = l.newBlock()
+	}
+	l.previewQueues = append(l.previewQueues, queue)
+	l.leftKeys = append(l.leftKeys, newRepeatableKey(l.initialLeftRightDelay, l.shortLeftRightDelay))
+	l.rightKeys = append(l.rightKeys, newRepeatableKey(l.initialLeftRightDelay, l.shortLeftRightDelay))
+	l.downKeys = append(l.downKeys, newRepeatableKey(l.initialDownDelay, l.shortDownDelay))
+	l.downKeyPlayers = append(l.downKeyPlayers, player)
+	if len(l.blockControls) > 0 {
+		l.AddBlockControl(BlockControl{Player: player, Block: block})
+	}
+
+	blocks := append(l.copyBlocks(), Block{})
+	l.resize(l.playerCount+1, blocks, func(field int) int { return field })
+	l.resetBlockToPreview(block)
+	if !l.moveUpOutOfOtherBlocks(block) {
+		l.endGame(block)
+	}
+	if o, ok := l.scorer.(PlayerObserver); ok {
+		o.PlayerAdded(player)
+	}
+	return player, nil
+}
+
+// RemovePlayer lets the player leave the running game. Players with higher
+// indices move down by one. The blocks that only this player controls are
+// removed, by default this is the block with the player's index. Blocks with
+// higher indices move down as well and the board is resized to the BoardSize
+// set for the new number of blocks, like in AddPlayer. The pieces that the
+// removed blocks placed on the board are turned into Garbage.
+func (l *Logic) RemovePlayer(player int) error {
+	if l.physics == nil {
+		return errors.New("no game is running")
+	}
+	if player < 0 || player >= l.players() {
+		return fmt.Errorf("there is no player %v", player)
+	}
+	if l.players() == 1 {
+		return errors.New("the last player can not be removed")
+	}
+	removed := l.blocksOnlyControlledBy(player)
+	if len(removed) > 0 {
+		if len(removed) == l.playerCount {
+			return errors.New("the last block can not be removed")
+		}
+		if err := l.checkPlayerCountChange(l.playerCount - len(removed)); err != nil {
+			return err
+		}
+	}
+	l.releaseDownKeysOf(player)
+	l.removeBlockControlsOf(player, removed)
+	blocks := l.copyBlocks()
+	for i := len(removed) - 1; i >= 0; i-- {
+		l.removeBlockState(removed[i])
+		blocks = withoutBlock(blocks, removed[i])
+	}
+	if len(removed) > 0 {
+		l.resize(l.playerCount-len(removed), blocks, func(field int) int {
+			if contains(removed, field) {
+				return Garbage
+			}
+			return field - countBelow(removed, field)
+		})
+	}
+	if o, ok := l.scorer.(PlayerObserver); ok {
+		o.PlayerRemoved(player)
+	}
+	return nil
+}
+
+// blocksOnlyControlledBy returns the player's blocks, in ascending order, that
+// no other player controls.
+func (l *Logic) blocksOnlyControlledBy(player int) []int {
+	var blocks []int
+	for _, b := range l.blocksFor(player, anyCommand) {
+		if players := l.playersFor(b, anyCommand); len(players) == 1 {
+			blocks = append(blocks, b)
+		}
+	}
+	sort.Ints(blocks)
+	return blocks
+}
+
+func (l *Logic) removeBlockState(block int) {
+	l.hasDroppedThisFrame = withoutBool(l.hasDroppedThisFrame, block)
+	l.heldBlocks = withoutBlock(l.heldBlocks, block)
+	l.unplacedBlocks = withoutBlock(l.unplacedBlocks, block)
+	l.hasHeld = withoutBool(l.hasHeld, block)
+	l.grounded = withoutBool(l.grounded, block)
+	l.lockTimers = withoutInt(l.lockTimers, block)
+	l.lockResets = withoutInt(l.lockResets, block)
+	l.lastMoveWasRotation = withoutBool(l.lastMoveWasRotation, block)
+	l.softDrops = withoutInt(l.softDrops, block)
+	l.hardDrops = withoutInt(l.hardDrops, block)
+	l.previewQueues = append(l.previewQueues[:block], l.previewQueues[block+1:]...)
+	l.leftKeys = withoutKey(l.leftKeys, block)
+	l.rightKeys = withoutKey(l.rightKeys, block)
+	l.downKeys = withoutKey(l.downKeys, block)
+	l.downKeyPlayers = withoutInt(l.downKeyPlayers, block)
+}
+
+func countBelow(values []int, limit int) int {
+	n := 0
+	for _, v := range values {
+		if v < limit {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *Logic) checkPlayerCountChange(players int) error {
+	if err := l.validateFor(players); err != nil {
+		return err
+	}
+	if len(l.fullLines) > 0 {
+		return errors.New("players can not change while lines are removed")
+	}
+	return nil
+}
+
+func (l *Logic) copyBlocks() []Block {
+	blocks := make([]Block, len(l.physics.Blocks()))
+	for i, b := range l.physics.Blocks() {
+		blocks[i] = b.Copy()
+	}
+	return blocks
+}
+
+// resize replaces the physics with one for the given number of players. The
+// fields of the old board are copied, centered horizontally and converted with
+// the given function. The blocks are moved along with the board. Blocks that do
+// not fit anymore are placed at their start positions.
+func (l *Logic) resize(players int, blocks []Block, convert func(field int) int) {
+	old := l.physics.board
+	oldW, oldH := old.Size()
+	l.playerCount = players
+	l.createPhysics(l.sizes[players])
+	w, h := l.physics.board.Size()
+	dx := (w - oldW) / 2
+	for y := 0; y < oldH && y < h; y++ {
+		for x := 0; x < oldW; x++ {
+			if 0 <= x+dx && x+dx < w && old.At(x, y) != NoPlayer {
+				l.physics.board.SetAt(x+dx, y, convert(old.At(x, y)))
+			}
+		}
+	}
+	for i, b := range blocks {
+		b.MoveBy(dx, 0)
+		l.physics.SetBlock(i, b)
+	}
+	for i := range blocks {
+		if l.physics.isInWall(i) || l.physics.isInSolidPartOfBoard(i) ||
+			l.physics.isInOtherBlock(i) {
+			l.placeAtStart(i, l.unplacedBlocks[i])
+			if !l.moveUpOutOfOtherBlocks(i) {
+				l.endGame(i)
+			}
+		}
+	}
+}
+
+// releaseDownKeysOf releases the down keys that the removed player is holding
+// for other blocks and renumbers the players that follow.
+func (l *Logic) releaseDownKeysOf(player int) {
+	for block, p := range l.downKeyPlayers {
+		if p == player {
+			l.downKeys[block].Release()
+		} else if p > player {
+			l.downKeyPlayers[block]--
+		}
+	}
+}
+
+// removeBlockControlsOf removes the controls of the removed player and moves
+// the following players down by one. The controls of the remaining blocks are
+// renumbered like the blocks.
+func (l *Logic) removeBlockControlsOf(player int, removedBlocks []int) {
+	var controls []BlockControl
+	for _, c := range l.blockControls {
+		if c.Player != player {
+			if c.Player > player {
+				c.Player--
+			}
+			c.Block -= countBelow(removedBlocks, c.Block)
+			controls = append(controls, c)
+		}
+	}
+	l.blockControls = controls
+}
+
+func withoutBool(s []bool, i int) []bool {
+	return append(s[:i], s[i+1:]...)
+}
+
+func withoutInt(s []int, i int) []int {
+	return append(s[:i], s[i+1:]...)
+}
+
+func withoutBlock(s []Block, i int) []Block {
+	return append(s[:i], s[i+1:]...)
+}
+
+func withoutKey(s []*repeatableKey, i int) []*repeatableKey {
+	return append(s[:i], s[i+1:]...)
+}
@@ -0,0 +1,144 @@
+package game
+
+import "testing"
+
+func TestAddedPlayerGetsBlockOnWiderBoard(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 1}})
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{4, 2})
+	logic.SetBlockStartPositions(2, []Point{{0, 1}, {3, 1}})
+	logic.StartNewGame(1)
+	logic.Board().SetAt(1, 0, 0)
+	player, err := logic.AddPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkInt(t, player, 1, "new player")
+	checkGame(t, logic, "board and block moved to the center",
+		".0.1",
+		"..0.",
+	)
+	checkInt(t, len(logic.PreviewBlocks()), 2, "preview blocks")
+	logic.Update(InputEvent{1, HardDrop})
+	logic.Update()
+	checkGame(t, logic, "new player can play",
+		".0.1",
+		"..01",
+	)
+}
+
+func TestRemovedPlayersPiecesBecomeGarbage(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{4, 2}, []Point{{0, 1}, {3, 1}})
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{2, 2})
+	logic.SetBlockStartPositions(1, []Point{{0, 1}})
+	logic.StartNewGame(2)
+	logic.Board().SetAt(1, 0, 0)
+	logic.Board().SetAt(2, 0, 1)
+	if err := logic.RemovePlayer(0); err != nil {
+		t.Fatal(err)
+	}
+	checkGame(t, logic, "player 1 is now player 0, its block did not fit",
+		"0.",
+		"G0",
+	)
+	checkInt(t, len(logic.PreviewBlocks()), 1, "preview blocks")
+}
+
+func TestPlayersCanNotChangeWithoutConfiguredBoard(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 1}})
+	logic.StartNewGame(1)
+	if _, err := logic.AddPlayer(); err == nil {
+		t.Error("player added without board size for 2 players")
+	}
+	if err := logic.RemovePlayer(0); err == nil {
+		t.Error("last player removed")
+	}
+	if err := logic.RemovePlayer(3); err == nil {
+		t.Error("unknown player removed")
+	}
+}
+
+func TestScorerForgetsRemovedPlayers(t *testing.T) {
+	logic := createSingleBlockGame(3, BoardSize{3, 2}, []Point{{0, 1}, {1, 1}, {2, 1}})
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{3, 2})
+	logic.SetBlockStartPositions(2, []Point{{0, 1}, {2, 1}})
+	scorer := NewTeamScorer()
+	scorer.AssignPlayerToTeam(2, 1)
+	logic.SetScorer(scorer)
+	logic.StartNewGame(3)
+	if err := logic.RemovePlayer(1); err != nil {
+		t.Fatal(err)
+	}
+	scorer.LinesRemoved([][]int{{}, {1}})
+	checkInt(t, scorer.ScoreForTeam(1), lineScore(1), "team of former player 2")
+}
+
+func TestRemovedPlayerTakesOnlyTheBlocksItControlsAlone(t *testing.T) {
+	logic := createSingleBlockGame(2, BoardSize{4, 2}, []Point{{0, 1}, {3, 1}})
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{2, 2})
+	logic.SetBlockStartPositions(1, []Point{{0, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 1})
+	logic.AddBlockControl(BlockControl{Player: 1, Block: 0})
+	logic.StartNewGame(2)
+	if err := logic.RemovePlayer(1); err != nil {
+		t.Fatal(err)
+	}
+	logic.Update(InputEvent{1, HardDrop})
+	checkGame(t, logic, "removed player controls nothing",
+		"0.",
+		"..",
+	)
+	logic.Update(InputEvent{0, HardDrop})
+	checkGame(t, logic, "remaining player controls its block",
+		"..",
+		"0.",
+	)
+}
+
+func TestPlayerSharingItsBlockLeavesWithoutRemovingIt(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0})
+	logic.AddBlockControl(BlockControl{Player: 1, Block: 0})
+	logic.StartNewGame(1)
+	if err := logic.RemovePlayer(0); err != nil {
+		t.Fatal(err)
+	}
+	logic.Update(InputEvent{0, RightPressed})
+	checkGame(t, logic, "former player 1 is now player 0",
+		".0",
+		"..",
+	)
+	if err := logic.RemovePlayer(0); err == nil {
+		t.Error("last player removed")
+	}
+}
+
+func TestPlayersCanNotChangeBeforeGameStarts(t *testing.T) {
+	logic := NewSeededLogic(NewSevenBagRandomizer)
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{10, 20})
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{10, 20})
+	if _, err := logic.AddPlayer(); err == nil {
+		t.Error("player added before game start")
+	}
+	if err := logic.RemovePlayer(0); err == nil {
+		t.Error("player removed before game start")
+	}
+}
+
+func TestAddedPlayerControlsNewBlockIfThereAreBlockControls(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 1}})
+	logic.SetBoardSizeForPlayerCount(2, BoardSize{2, 2})
+	logic.SetBlockStartPositions(2, []Point{{0, 1}, {1, 1}})
+	logic.AddBlockControl(BlockControl{Player: 0, Block: 0})
+	logic.AddBlockControl(BlockControl{Player: 1, Block: 0})
+	logic.StartNewGame(1)
+	player, err := logic.AddPlayer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkInt(t, player, 2, "new player")
+	logic.Update(InputEvent{2, HardDrop})
+	checkGame(t, logic, "new player dropped the new block",
+		"0.",
+		".1",
+	)
+}
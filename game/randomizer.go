@@ -0,0 +1,122 @@
+package game
+
+import "math/rand"
+
+// The randomizers in this file create the seven standard blocks of the
+// blockFactory in a random order. Given the same seed, they always create the
+// same sequence of blocks.
+//
+// Logic uses a single BlockFactory for all players which means that all players
+// share one bag. This way the blocks that a team gets are evenly distributed,
+// no matter how many players take their blocks from it.
+
+// NewPureRandomizer picks each block uniformly at random, independent of the
+// blocks created before.
+func NewPureRandomizer(seed int64) BlockFactory {
+	return NewBlockFactory().PureRandomizer(seed)
+}
+
+// NewSevenBagRandomizer puts one of each of the seven blocks in a bag and
+// draws them in random order. When the bag is empty, it is refilled.
+func NewSevenBagRandomizer(seed int64) BlockFactory {
+	return NewBlockFactory().SevenBagRandomizer(seed)
+}
+
+// NewFourteenBagRandomizer works like NewSevenBagRandomizer but with two of
+// each block in the bag.
+func NewFourteenBagRandomizer(seed int64) BlockFactory {
+	return NewBlockFactory().FourteenBagRandomizer(seed)
+}
+
+// NewHistoryRandomizer remembers the last historySize blocks. If a randomly
+// picked block is one of them it is re-rolled, picking at most rolls times in
+// total. The history starts with Z blocks only and the first block is never an O, S or Z,
+// like in The Grand Master which uses a history of 4 and 4 rolls.
+func NewHistoryRandomizer(seed int64, historySize, rolls int) BlockFactory {
+	return NewBlockFactory().HistoryRandomizer(seed, historySize, rolls)
+}
+
+// The methods of blockFactory work like the functions above but create blocks
+// with the factory's KickSystem.
+
+func (f blockFactory) PureRandomizer(seed int64) BlockFactory {
+	r := rand.New(rand.NewSource(seed))
+	creators := f.standardBlockCreators()
+	return func() Block {
+		return creators[r.Intn(len(creators))]()
+	}
+}
+
+func (f blockFactory) SevenBagRandomizer(seed int64) BlockFactory {
+	return f.bagRandomizer(seed, 1)
+}
+
+func (f blockFactory) FourteenBagRandomizer(seed int64) BlockFactory {
+	return f.bagRandomizer(seed, 2)
+}
+
+func (f blockFactory) bagRandomizer(seed int64, copies int) BlockFactory {
+	r := rand.New(rand.NewSource(seed))
+	creators := f.standardBlockCreators()
+	var bag []int
+	return func() Block {
+		if len(bag) == 0 {
+			bag = r.Perm(len(creators) * copies)
+		}
+		next := bag[0]
+		bag = bag[1:]
+		return creators[next%len(creators)]()
+	}
+}
+
+func (f blockFactory) HistoryRandomizer(seed int64, historySize, rolls int) BlockFactory {
+	r := rand.New(rand.NewSource(seed))
+	creators := f.standardBlockCreators()
+	history := make([]int, historySize)
+	for i := range history {
+		history[i] = zBlock
+	}
+	first := true
+	return func() Block {
+		var next int
+		if first {
+			next = firstBlocks[r.Intn(len(firstBlocks))]
+			first = false
+		} else {
+			next = r.Intn(len(creators))
+			for i := 1; i < rolls && contains(history, next); i++ {
+				next = r.Intn(len(creators))
+			}
+		}
+		if len(history) > 0 {
+			copy(history, history[1:])
+			history[len(history)-1] = next
+		}
+		return creators[next]()
+	}
+}
+
+// These are the indices of the blocks in standardBlockCreators.
+const (
+	oBlock = iota
+	iBlock
+	lBlock
+	jBlock
+	tBlock
+	sBlock
+	zBlock
+)
+
+var firstBlocks = []int{iBlock, lBlock, jBlock, tBlock}
+
+func (f blockFactory) standardBlockCreators() []func() Block {
+	return []func() Block{
+		f.CreateO,
+		f.CreateI,
+		f.CreateL,
+		f.CreateJ,
+		f.CreateT,
+		f.CreateS,
+		f.CreateZ,
+	}
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSevenBagContainsEachBlockOnce(t *testing.T) {
+	next := NewSevenBagRandomizer(1)
+	for bag := 0; bag < 10; bag++ {
+		checkEachBlockCount(t, drawBlocks(next, 7), 1)
+	}
+}
+
+func TestFourteenBagContainsEachBlockTwice(t *testing.T) {
+	next := NewFourteenBagRandomizer(1)
+	for bag := 0; bag < 10; bag++ {
+		checkEachBlockCount(t, drawBlocks(next, 14), 2)
+	}
+}
+
+func TestPureRandomizerCreatesAllBlocks(t *testing.T) {
+	counts := countBlocks(drawBlocks(NewPureRandomizer(1), 700))
+	if len(counts) != 7 {
+		t.Error("expected all 7 blocks but got", len(counts))
+	}
+}
+
+func TestHistoryRandomizerAvoidsRecentBlocks(t *testing.T) {
+	blocks := drawBlocks(NewHistoryRandomizer(1, 4, 1000), 100)
+	for i := 4; i < len(blocks); i++ {
+		for _, recent := range blocks[i-4 : i] {
+			if blocks[i] == recent {
+				t.Fatal("block", i, "repeats one of the last 4 blocks", blocks)
+			}
+		}
+	}
+}
+
+func TestHistoryRandomizerWithOneRollIgnoresHistory(t *testing.T) {
+	blocks := drawBlocks(NewHistoryRandomizer(1, 4, 1), 100)
+	for i := 4; i < len(blocks); i++ {
+		for _, recent := range blocks[i-4 : i] {
+			if blocks[i] == recent {
+				return
+			}
+		}
+	}
+	t.Error("no block repeats one of the last 4 blocks", blocks)
+}
+
+func TestHistoryRandomizerNeverStartsWithOSOrZ(t *testing.T) {
+	f := NewBlockFactory()
+	forbidden := []string{
+		blockID(f.CreateO()),
+		blockID(f.CreateS()),
+		blockID(f.CreateZ()),
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		first := drawBlocks(NewHistoryRandomizer(seed, 4, 4), 1)[0]
+		for _, f := range forbidden {
+			if first == f {
+				t.Error("seed", seed, "started with", first)
+			}
+		}
+	}
+}
+
+func TestRandomizersAreDeterministicForTheSameSeed(t *testing.T) {
+	randomizers := map[string]func(seed int64) BlockFactory{
+		"pure":   NewPureRandomizer,
+		"7-bag":  NewSevenBagRandomizer,
+		"14-bag": NewFourteenBagRandomizer,
+		"history": func(seed int64) BlockFactory {
+			return NewHistoryRandomizer(seed, 4, 4)
+		},
+	}
+	for name, create := range randomizers {
+		a := fmt.Sprint(drawBlocks(create(123), 50))
+		b := fmt.Sprint(drawBlocks(create(123), 50))
+		c := fmt.Sprint(drawBlocks(create(456), 50))
+		if a != b {
+			t.Error(name, "created different blocks for the same seed")
+		}
+		if a == c {
+			t.Error(name, "created the same blocks for different seeds")
+		}
+	}
+}
+
+func drawBlocks(next BlockFactory, count int) []string {
+	blocks := make([]string, count)
+	for i := range blocks {
+		blocks[i] = blockID(next())
+	}
+	return blocks
+}
+
+func blockID(b Block) string {
+	return fmt.Sprint(b.Points)
+}
+
+func countBlocks(blocks []string) map[string]int {
+	counts := make(map[string]int)
+	for _, b := range blocks {
+		counts[b]++
+	}
+	return counts
+}
+
+func checkEachBlockCount(t *testing.T, blocks []string, expected int) {
+	counts := countBlocks(blocks)
+	if len(counts) != 7 {
+		t.Error("expected all 7 blocks but got", blocks)
+	}
+	for b, count := range counts {
+		if count != expected {
+			t.Error("block", b, "occured", count, "times instead of", expected)
+		}
+	}
+}
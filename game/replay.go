@@ -0,0 +1,246 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReplayVersion is written to every replay file. Files with a different
+// version can not be read.
+const ReplayVersion = 1
+
+// Replay contains the configuration of a game and the input events of every
+// frame. Playing it back with a Logic created by NewSeededLogic, using the same
+// SeededBlockFactory as the recorded game, reproduces the game exactly. The
+// DropTimer and LineAnimation are not recorded, the caller has to set up the
+// Logic with ones that behave identically to the recorded game's.
+type Replay struct {
+	Version        int
+	Players        int
+	Seed           int64
+	BoardSize      BoardSize
+	StartPositions []Point
+	KeyDelays      KeyDelays
+	PreviewCount   int
+	PausePolicy    PausePolicy
+	HiddenRows     int
+	BlockControls  []BlockControl
+	LockDelay      LockDelay
+	PlayerChanges  []PlayerChange
+	Frames         [][]InputEvent
+}
+
+// PlayerChange records a player joining or leaving the game right before the
+// input events of the given frame. The board size and start positions are the
+// ones set for the new number of players.
+type PlayerChange struct {
+	Frame          int
+	Join           bool
+	Player         int
+	BoardSize      BoardSize
+	StartPositions []Point
+}
+
+type LockDelay struct {
+	Updates     int
+	ResetOnMove bool
+	MaxResets   int
+}
+
+type KeyDelays struct {
+	InitialLeftRight int
+	ShortLeftRight   int
+	InitialDown      int
+	ShortDown        int
+}
+
+// ReadReplay reads a replay that was written with Replay.Write.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	var replay Replay
+	if err := json.NewDecoder(r).Decode(&replay); err != nil {
+		return nil, err
+	}
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version %v, expected %v",
+			replay.Version, ReplayVersion)
+	}
+	return &replay, nil
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// Recorder is used in place of a Logic. It forwards all calls to the Logic and
+// records the configuration and input events to a Replay.
+type Recorder struct {
+	logic  *Logic
+	replay *Replay
+}
+
+func NewRecorder(l *Logic) *Recorder {
+	return &Recorder{logic: l}
+}
+
+// StartNewGame starts a new game in the Logic and a new Replay.
+func (r *Recorder) StartNewGame(players int) error {
+	if err := r.logic.StartNewGame(players); err != nil {
+		return err
+	}
+	r.replay = &Replay{
+		Version:        ReplayVersion,
+		Players:        players,
+		Seed:           r.logic.seed,
+		BoardSize:      r.logic.sizes[players],
+		StartPositions: r.logic.startPositions[players],
+		KeyDelays: KeyDelays{
+			InitialLeftRight: r.logic.initialLeftRightDelay,
+			ShortLeftRight:   r.logic.shortLeftRightDelay,
+			InitialDown:      r.logic.initialDownDelay,
+			ShortDown:        r.logic.shortDownDelay,
+		},
+		PreviewCount:  r.logic.previewCount,
+		PausePolicy:   r.logic.pausePolicy,
+		HiddenRows:    r.logic.hiddenRows,
+		BlockControls: append([]BlockControl{}, r.logic.blockControls...),
+		LockDelay: LockDelay{
+			Updates:     r.logic.lockDelay,
+			ResetOnMove: r.logic.lockResetOnMove,
+			MaxResets:   r.logic.maxLockResets,
+		},
+	}
+	return nil
+}
+
+var errNotRecording = errors.New("no game was started with the Recorder")
+
+// AddPlayer lets a new player join the game in the Logic and records it.
+func (r *Recorder) AddPlayer() (player int, err error) {
+	if r.replay == nil {
+		return 0, errNotRecording
+	}
+	player, err = r.logic.AddPlayer()
+	if err == nil {
+		r.recordPlayerChange(true, player)
+	}
+	return
+}
+
+// RemovePlayer lets the player leave the game in the Logic and records it.
+func (r *Recorder) RemovePlayer(player int) error {
+	if r.replay == nil {
+		return errNotRecording
+	}
+	err := r.logic.RemovePlayer(player)
+	if err == nil {
+		r.recordPlayerChange(false, player)
+	}
+	return err
+}
+
+func (r *Recorder) recordPlayerChange(join bool, player int) {
+	players := r.logic.playerCount
+	r.replay.PlayerChanges = append(r.replay.PlayerChanges, PlayerChange{
+		Frame:          len(r.replay.Frames),
+		Join:           join,
+		Player:         player,
+		BoardSize:      r.logic.sizes[players],
+		StartPositions: r.logic.startPositions[players],
+	})
+}
+
+// Update records a copy of the events and updates the Logic. It does nothing
+// before a game was started with StartNewGame.
+func (r *Recorder) Update(events ...InputEvent) {
+	if r.replay == nil {
+		return
+	}
+	r.replay.Frames = append(r.replay.Frames, append([]InputEvent(nil), events...))
+	r.logic.Update(events...)
+}
+
+// Replay returns the replay of the current game, up to the last Update.
+func (r *Recorder) Replay() *Replay {
+	return r.replay
+}
+
+// ReplayPlayer feeds the recorded input events of a Replay into a Logic, one
+// frame per Update.
+type ReplayPlayer struct {
+	logic  *Logic
+	replay *Replay
+	frame  int
+}
+
+// NewReplayPlayer configures the Logic like the recorded game and starts a new
+// game. It returns an error if the recorded configuration is invalid.
+func NewReplayPlayer(l *Logic, r *Replay) (*ReplayPlayer, error) {
+	l.SetBoardSizeForPlayerCount(r.Players, r.BoardSize)
+	l.SetBlockStartPositions(r.Players, r.StartPositions)
+	l.SetInitialLeftRightKeyDelay(r.KeyDelays.InitialLeftRight)
+	l.SetShortLeftRightKeyDelay(r.KeyDelays.ShortLeftRight)
+	l.SetInitialDownKeyDelay(r.KeyDelays.InitialDown)
+	l.SetShortDownKeyDelay(r.KeyDelays.ShortDown)
+	l.SetPreviewCount(r.PreviewCount)
+	l.SetPausePolicy(r.PausePolicy)
+	l.SetHiddenRows(r.HiddenRows)
+	l.ClearBlockControls()
+	for _, c := range r.BlockControls {
+		l.AddBlockControl(c)
+	}
+	l.SetLockDelay(r.LockDelay.Updates)
+	l.SetLockDelayResetOnMove(r.LockDelay.ResetOnMove)
+	l.SetMaxLockDelayResets(r.LockDelay.MaxResets)
+	players := r.Players
+	for _, c := range r.PlayerChanges {
+		if c.Join {
+			players++
+		} else {
+			players--
+		}
+		l.SetBoardSizeForPlayerCount(players, c.BoardSize)
+		l.SetBlockStartPositions(players, c.StartPositions)
+		if err := l.validateFor(players); err != nil {
+			return nil, err
+		}
+	}
+	l.SetSeed(r.Seed)
+	if err := l.StartNewGame(r.Players); err != nil {
+		return nil, err
+	}
+	return &ReplayPlayer{logic: l, replay: r}, nil
+}
+
+// Update plays the next recorded frame. It returns false if all frames have
+// been played.
+func (p *ReplayPlayer) Update() bool {
+	if p.IsOver() {
+		return false
+	}
+	p.changePlayers()
+	p.logic.Update(p.replay.Frames[p.frame]...)
+	p.frame++
+	return true
+}
+
+// changePlayers lets the players join and leave that did so before the current
+// frame. The configuration for all numbers of players was validated in
+// NewReplayPlayer and the changes succeeded in the recorded game so they can
+// not fail here.
+func (p *ReplayPlayer) changePlayers() {
+	for _, c := range p.replay.PlayerChanges {
+		if c.Frame == p.frame {
+			if c.Join {
+				p.logic.AddPlayer()
+			} else {
+				p.logic.RemovePlayer(c.Player)
+			}
+		}
+	}
+}
+
+func (p *ReplayPlayer) IsOver() bool {
+	return p.frame >= len(p.replay.Frames)
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecordedGameIsPlayedBackIdentically(t *testing.T) {
+	original, _ := createSeededTwoPlayerGame(0)
+	original.SetInitialLeftRightKeyDelay(3)
+	original.SetPreviewCount(3)
+	original.SetSeed(99)
+	recorder := NewRecorder(original)
+	recorder.StartNewGame(2)
+	for frame := 0; frame < 300; frame++ {
+		recorder.Update(scriptedInputs(frame)...)
+	}
+
+	var file bytes.Buffer
+	if err := recorder.Replay().Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := NewSeededLogic(NewSevenBagRandomizer)
+	replayed.SetDropTimer(&spyDropTimer{isTimeForDrop: true})
+	player, err := NewReplayPlayer(replayed, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := 0
+	for player.Update() {
+		frames++
+	}
+	checkInt(t, frames, 300, "played frames")
+	if gameToString(replayed) != gameToString(original) {
+		t.Error("replay differs", gameToString(replayed), gameToString(original))
+	}
+	checkBlocksEqual(t, replayed.PreviewQueue(1), original.PreviewQueue(1)...)
+}
+
+func TestPlayersJoiningAndLeavingArePlayedBack(t *testing.T) {
+	original, _ := createSeededTwoPlayerGame(0)
+	original.SetBoardSizeForPlayerCount(1, BoardSize{8, 18})
+	original.SetBoardSizeForPlayerCount(3, BoardSize{13, 18})
+	recorder := NewRecorder(original)
+	recorder.StartNewGame(2)
+	for frame := 0; frame < 60; frame++ {
+		if frame == 20 {
+			if _, err := recorder.AddPlayer(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if frame == 40 {
+			if err := recorder.RemovePlayer(0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		recorder.Update(scriptedInputs(frame)...)
+	}
+
+	var file bytes.Buffer
+	if err := recorder.Replay().Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := NewSeededLogic(NewSevenBagRandomizer)
+	replayed.SetDropTimer(&spyDropTimer{isTimeForDrop: true})
+	player, err := NewReplayPlayer(replayed, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for player.Update() {
+	}
+	if gameToString(replayed) != gameToString(original) {
+		t.Error("replay differs", gameToString(replayed), gameToString(original))
+	}
+}
+
+func TestGameWithDefaultStartPositionsIsPlayedBack(t *testing.T) {
+	original := NewLogic(alwaysReturn(block(0, 0)))
+	original.SetBoardSizeForPlayerCount(2, BoardSize{4, 4})
+	recorder := NewRecorder(original)
+	if err := recorder.StartNewGame(2); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Update(InputEvent{0, HardDrop}, InputEvent{1, LeftPressed})
+	recorder.Update()
+
+	var file bytes.Buffer
+	if err := recorder.Replay().Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadReplay(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := NewLogic(alwaysReturn(block(0, 0)))
+	player, err := NewReplayPlayer(replayed, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for player.Update() {
+	}
+	if gameToString(replayed) != gameToString(original) {
+		t.Error("replay differs", gameToString(replayed), gameToString(original))
+	}
+}
+
+func TestRecorderCopiesEvents(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{1, 2}})
+	recorder := NewRecorder(logic)
+	recorder.StartNewGame(1)
+	events := []InputEvent{{0, LeftPressed}}
+	recorder.Update(events...)
+	events[0] = InputEvent{0, RightPressed}
+	recorder.Update(events...)
+	frames := recorder.Replay().Frames
+	if len(frames) != 2 || frames[0][0].Command != LeftPressed ||
+		frames[1][0].Command != RightPressed {
+		t.Error("recorded frames are", frames)
+	}
+}
+
+func TestRecorderDoesNothingBeforeGameStarts(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{1, 2}})
+	recorder := NewRecorder(logic)
+	recorder.Update(InputEvent{0, LeftPressed})
+	if _, err := recorder.AddPlayer(); err == nil {
+		t.Error("player added before game start")
+	}
+	if err := recorder.RemovePlayer(0); err == nil {
+		t.Error("player removed before game start")
+	}
+	if recorder.Replay() != nil {
+		t.Error("replay recorded before game start")
+	}
+}
+
+func TestReplayPlayerIsOverAfterLastFrame(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	recorder := NewRecorder(logic)
+	recorder.StartNewGame(1)
+	recorder.Update(InputEvent{0, RightPressed})
+	player, err := NewReplayPlayer(logic, recorder.Replay())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.IsOver() {
+		t.Fatal("over before first frame")
+	}
+	if !player.Update() {
+		t.Fatal("first frame not played")
+	}
+	if !player.IsOver() || player.Update() {
+		t.Error("not over after last frame")
+	}
+	checkGame(t, logic, "replayed",
+		".0.",
+		"...",
+		"...",
+	)
+}
+
+func TestReplayWithOtherVersionCanNotBeRead(t *testing.T) {
+	_, err := ReadReplay(strings.NewReader(`{"Version":0,"Players":1}`))
+	if err == nil {
+		t.Error("error expected")
+	}
+}
@@ -0,0 +1,105 @@
+package game
+
+// Mode decides when a Session is won, given the progress made so far.
+type Mode func(p Progress) bool
+
+// Progress is what a Session achieved so far. Frames are counted while the
+// game is neither paused nor over.
+type Progress struct {
+	Frames int
+	Lines  int
+	Level  int
+	Score  int
+}
+
+// Sprint is won when the given number of lines was removed. The fewer frames
+// it took, the better.
+func Sprint(lines int) Mode {
+	return func(p Progress) bool {
+		return p.Lines >= lines
+	}
+}
+
+// Ultra ends after the given number of frames. The higher the score, the
+// better.
+func Ultra(frames int) Mode {
+	return func(p Progress) bool {
+		return p.Frames >= frames
+	}
+}
+
+// Marathon is won when the given level is completed, i.e. when the level rises
+// above it. It needs a Logic with a LevelDropTimer.
+func Marathon(maxLevel int) Mode {
+	return func(p Progress) bool {
+		return p.Level > maxLevel
+	}
+}
+
+// Session plays a game in a Mode. It ends when the Mode is won or when the
+// game is over before that.
+type Session struct {
+	logic    *Logic
+	mode     Mode
+	score    func() int
+	progress Progress
+	over     bool
+	won      bool
+}
+
+func NewSession(l *Logic, m Mode) *Session {
+	return &Session{logic: l, mode: m}
+}
+
+// SetScoreFunc sets the function that reports the current score, e.g. a
+// CoopScorer's Score method. Without it, the score is always 0.
+func (s *Session) SetScoreFunc(score func() int) {
+	s.score = score
+}
+
+func (s *Session) StartNewGame(players int) error {
+	if err := s.logic.StartNewGame(players); err != nil {
+		return err
+	}
+	s.progress = Progress{Level: s.logic.Level()}
+	s.over = false
+	s.won = false
+	return nil
+}
+
+// Update updates the Logic with the events as long as the Session is not over.
+func (s *Session) Update(events ...InputEvent) {
+	if s.over {
+		return
+	}
+	s.logic.Update(events...)
+	if !s.logic.IsPaused() && !s.logic.IsGameOver() {
+		s.progress.Frames++
+	}
+	s.progress.Lines += len(s.logic.RemovedLines())
+	s.progress.Level = s.logic.Level()
+	if s.score != nil {
+		s.progress.Score = s.score()
+	}
+	if s.mode(s.progress) {
+		s.over = true
+		s.won = true
+	} else if s.logic.IsGameOver() {
+		s.over = true
+	}
+}
+
+func (s *Session) Progress() Progress {
+	return s.progress
+}
+
+// IsOver returns true if the Mode was won or the game is over.
+func (s *Session) IsOver() bool {
+	return s.over
+}
+
+// IsWon returns true if the Session ended because the Mode was won, as opposed
+// to the game being over.
+func (s *Session) IsWon() bool {
+	return s.won
+}
@@ -0,0 +1,84 @@
+package game
+
+import "testing"
+
+func TestSprintIsWonAfterRemovingLines(t *testing.T) {
+	s := NewSession(createSingleBlockGame(1, BoardSize{1, 2}, []Point{{0, 1}}), Sprint(2))
+	s.StartNewGame(1)
+	for i := 0; i < 10 && !s.IsOver(); i++ {
+		s.Update(InputEvent{0, HardDrop})
+	}
+	if !s.IsOver() || !s.IsWon() {
+		t.Fatal("sprint not won")
+	}
+	checkInt(t, s.Progress().Lines, 2, "lines")
+	checkInt(t, s.Progress().Frames, 3, "frames")
+}
+
+func TestUltraEndsAfterFrames(t *testing.T) {
+	s := NewSession(createSingleBlockGame(1, BoardSize{1, 5}, []Point{{0, 4}}), Ultra(3))
+	s.StartNewGame(1)
+	s.Update()
+	s.Update()
+	if s.IsOver() {
+		t.Fatal("ultra over too early")
+	}
+	s.Update()
+	if !s.IsOver() || !s.IsWon() {
+		t.Fatal("ultra not over")
+	}
+	s.Update()
+	checkInt(t, s.Progress().Frames, 3, "frames")
+}
+
+func TestPausedFramesAreNotCounted(t *testing.T) {
+	s := NewSession(createSingleBlockGame(1, BoardSize{1, 5}, []Point{{0, 4}}), Ultra(10))
+	s.StartNewGame(1)
+	s.Update()
+	s.Update(InputEvent{0, Pause})
+	s.Update()
+	s.Update(InputEvent{0, Pause})
+	checkInt(t, s.Progress().Frames, 2, "frames")
+}
+
+func TestMarathonIsWonAfterCompletingTheMaxLevel(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 2}, []Point{{0, 1}})
+	logic.SetDropTimer(NewLevelTimer(GuidelineSpeedCurve, 1))
+	s := NewSession(logic, Marathon(1))
+	s.StartNewGame(1)
+	s.Update(InputEvent{0, HardDrop})
+	s.Update()
+	if s.IsOver() {
+		t.Fatal("marathon over on level", s.Progress().Level)
+	}
+	s.Update(InputEvent{0, HardDrop})
+	s.Update()
+	if !s.IsWon() {
+		t.Error("marathon not won on level", s.Progress().Level)
+	}
+}
+
+func TestSessionIsLostWhenGameIsOver(t *testing.T) {
+	s := NewSession(createSingleBlockGame(1, BoardSize{2, 1}, []Point{{0, 0}}), Sprint(10))
+	s.StartNewGame(1)
+	s.Update(InputEvent{0, HardDrop})
+	s.Update()
+	if !s.IsOver() {
+		t.Fatal("session not over")
+	}
+	if s.IsWon() {
+		t.Error("session won")
+	}
+}
+
+func TestSessionReportsScore(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{1, 2}, []Point{{0, 1}})
+	scorer := NewCoopScorer()
+	logic.SetScorer(scorer)
+	s := NewSession(logic, Sprint(10))
+	s.SetScoreFunc(scorer.Score)
+	s.StartNewGame(1)
+	s.Update(InputEvent{0, HardDrop})
+	s.Update()
+	checkInt(t, s.Progress().Score, lineScore(1), "score")
+}
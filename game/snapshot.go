@@ -0,0 +1,343 @@
+package game
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// SnapshotVersion is stored in every Snapshot. Snapshots with a different
+// version can not be restored.
+const SnapshotVersion = 1
+
+// Snapshot contains the complete state of a running game. It can be encoded as
+// JSON with the encoding/json package or in a compact binary form with
+// MarshalBinary.
+//
+// The drop timer, line animation and scorer are stored only if they implement
+// encoding.BinaryMarshaler and are restored only if they implement
+// encoding.BinaryUnmarshaler.
+//
+// The BlockFactory can not be stored. A Logic created with NewSeededLogic
+// re-creates its factory from the seed and skips the BlocksCreated blocks
+// already used. Other factories simply continue where they are.
+type Snapshot struct {
+	Version        int
+	Players        int
+	Seed           int64
+	BlocksCreated  int
+	Board          [][]int
+	HiddenRows     int
+	Blocks         []BlockState
+	PreviewQueues  [][]BlockState
+	HeldBlocks     []BlockState
+	UnplacedBlocks []BlockState
+	HasHeld        []bool
+	HasDropped     []bool
+	Grounded       []bool
+	LockTimers     []int
+	LockResets     []int
+	LastRotated    []bool
+	SoftDrops      []int
+	HardDrops      []int
+	FullLines      []int
+	LeftKeys       []KeyState
+	RightKeys      []KeyState
+	DownKeys       []KeyState
+	DownKeyPlayers []int
+	Paused         bool
+	GameOver       bool
+	DropTimer      []byte
+	LineAnimation  []byte
+	Scorer         []byte
+}
+
+// BlockState is a Block including its otherwise hidden rotation.
+type BlockState struct {
+	Shape          Shape
+	Points         []Point
+	RotationDeltas [][]Point
+	Kicks          [][]Point
+	Rotation       int
+}
+
+// KeyState is the repeat state of a player's key.
+type KeyState struct {
+	Timer        int
+	InitialDelay int
+	FastDelay    int
+	Down         bool
+}
+
+// snapshotData has no methods so it can be gob-encoded without calling
+// Snapshot.MarshalBinary recursively.
+type snapshotData Snapshot
+
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(snapshotData(s))
+	return buf.Bytes(), err
+}
+
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode((*snapshotData)(s))
+}
+
+// Snapshot copies the current game state. Changing the game afterwards does
+// not change the Snapshot.
+func (l *Logic) Snapshot() (Snapshot, error) {
+	s := Snapshot{
+		Version:        SnapshotVersion,
+		Players:        l.playerCount,
+		Seed:           l.seed,
+		BlocksCreated:  l.blocksCreated,
+		Board:          l.physics.board.Copy().(board).fields,
+		HiddenRows:     l.hiddenRows,
+		Blocks:         blockStates(l.physics.Blocks()),
+		HeldBlocks:     blockStates(l.heldBlocks),
+		UnplacedBlocks: blockStates(l.unplacedBlocks),
+		HasHeld:        copyBools(l.hasHeld),
+		HasDropped:     copyBools(l.hasDroppedThisFrame),
+		Grounded:       copyBools(l.grounded),
+		LockTimers:     append([]int{}, l.lockTimers...),
+		LockResets:     append([]int{}, l.lockResets...),
+		LastRotated:    copyBools(l.lastMoveWasRotation),
+		SoftDrops:      append([]int{}, l.softDrops...),
+		HardDrops:      append([]int{}, l.hardDrops...),
+		FullLines:      append([]int{}, l.fullLines...),
+		LeftKeys:       keyStates(l.leftKeys),
+		RightKeys:      keyStates(l.rightKeys),
+		DownKeys:       keyStates(l.downKeys),
+		DownKeyPlayers: append([]int{}, l.downKeyPlayers...),
+		Paused:         l.paused,
+		GameOver:       l.gameOver,
+	}
+	for _, queue := range l.previewQueues {
+		s.PreviewQueues = append(s.PreviewQueues, blockStates(queue))
+	}
+	var err error
+	if s.DropTimer, err = marshal(l.dropTimer); err != nil {
+		return s, err
+	}
+	if s.LineAnimation, err = marshal(l.lineAnimation); err != nil {
+		return s, err
+	}
+	s.Scorer, err = marshal(l.scorer)
+	return s, err
+}
+
+func marshal(x interface{}) ([]byte, error) {
+	if m, ok := x.(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	return nil, nil
+}
+
+// Restore sets the game state to the one in the Snapshot. The Logic has to be
+// configured like the one that the Snapshot was taken from.
+func (l *Logic) Restore(s Snapshot) error {
+	if err := s.check(); err != nil {
+		return err
+	}
+	if err := l.restoreComponents(s); err != nil {
+		return err
+	}
+	l.playerCount = s.Players
+	l.seed = s.Seed
+	l.resetBlockFactory()
+	if l.seededBlockFactory != nil {
+		for i := 0; i < s.BlocksCreated; i++ {
+			l.blockFactory()
+		}
+	}
+	l.blocksCreated = s.BlocksCreated
+
+	w, h := 0, len(s.Board)
+	if h > 0 {
+		w = len(s.Board[0])
+	}
+	l.hiddenRows = s.HiddenRows
+	l.createPhysics(BoardSize{w, h - s.HiddenRows})
+	l.physics.board = board{fields: s.Board}.Copy().(board)
+	l.physics.board.visible = h - s.HiddenRows
+	for i, b := range s.Blocks {
+		l.physics.SetBlock(i, b.block())
+	}
+	l.previewQueues = make([][]Block, len(s.PreviewQueues))
+	for i, queue := range s.PreviewQueues {
+		l.previewQueues[i] = blocksFromStates(queue)
+	}
+	l.heldBlocks = blocksFromStates(s.HeldBlocks)
+	l.unplacedBlocks = blocksFromStates(s.UnplacedBlocks)
+	l.hasHeld = copyBools(s.HasHeld)
+	l.hasDroppedThisFrame = copyBools(s.HasDropped)
+	l.grounded = copyBools(s.Grounded)
+	l.lockTimers = append([]int{}, s.LockTimers...)
+	l.lockResets = append([]int{}, s.LockResets...)
+	l.lastMoveWasRotation = copyBools(s.LastRotated)
+	l.softDrops = append([]int{}, s.SoftDrops...)
+	l.hardDrops = append([]int{}, s.HardDrops...)
+	l.fullLines = append([]int{}, s.FullLines...)
+	l.leftKeys = keysFromStates(s.LeftKeys)
+	l.rightKeys = keysFromStates(s.RightKeys)
+	l.downKeys = keysFromStates(s.DownKeys)
+	l.downKeyPlayers = append([]int{}, s.DownKeyPlayers...)
+	l.paused = s.Paused
+	l.gameOver = s.GameOver
+	return nil
+}
+
+// restoreComponents restores the drop timer, line animation and scorer. If one
+// of them can not be restored, all of them are set back to their former state.
+func (l *Logic) restoreComponents(s Snapshot) error {
+	components := []interface{}{l.dropTimer, l.lineAnimation, l.scorer}
+	states := [][]byte{s.DropTimer, s.LineAnimation, s.Scorer}
+	former := make([][]byte, len(components))
+	for i, c := range components {
+		var err error
+		if former[i], err = marshal(c); err != nil {
+			return err
+		}
+	}
+	for i, c := range components {
+		if err := unmarshal(c, states[i]); err != nil {
+			for j := i; j >= 0; j-- {
+				unmarshal(components[j], former[j])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) check() error {
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %v, expected %v",
+			s.Version, SnapshotVersion)
+	}
+	n := s.Players
+	if len(s.Blocks) != n || len(s.PreviewQueues) != n ||
+		len(s.HeldBlocks) != n || len(s.UnplacedBlocks) != n ||
+		len(s.HasHeld) != n || len(s.HasDropped) != n ||
+		len(s.Grounded) != n || len(s.LockTimers) != n || len(s.LockResets) != n ||
+		len(s.LastRotated) != n || len(s.SoftDrops) != n || len(s.HardDrops) != n ||
+		len(s.LeftKeys) != n || len(s.RightKeys) != n || len(s.DownKeys) != n ||
+		len(s.DownKeyPlayers) != n {
+		return errors.New("snapshot does not contain the state of all players")
+	}
+	for _, queue := range s.PreviewQueues {
+		if len(queue) == 0 {
+			return errors.New("snapshot contains empty preview queue")
+		}
+	}
+	if s.HiddenRows < 0 || s.HiddenRows > len(s.Board) {
+		return errors.New("snapshot contains invalid number of hidden rows")
+	}
+	for _, row := range s.Board {
+		if len(row) != len(s.Board[0]) {
+			return errors.New("snapshot contains board rows of different widths")
+		}
+	}
+	w := 0
+	if len(s.Board) > 0 {
+		w = len(s.Board[0])
+	}
+	for _, b := range s.Blocks {
+		for _, p := range b.Points {
+			if p.X < 0 || p.X >= w || p.Y < 0 {
+				return fmt.Errorf("snapshot contains block outside the board at %v", p)
+			}
+		}
+	}
+	blocks := append(append(append([]BlockState{}, s.Blocks...), s.HeldBlocks...),
+		s.UnplacedBlocks...)
+	for _, queue := range s.PreviewQueues {
+		blocks = append(blocks, queue...)
+	}
+	for _, b := range blocks {
+		if err := b.check(); err != nil {
+			return fmt.Errorf("snapshot contains invalid block: %v", err)
+		}
+	}
+	return nil
+}
+
+// check makes sure that the block can be rotated without going out of bounds.
+// Blocks without points are valid, they are used for empty held blocks.
+func (s BlockState) check() error {
+	rotations := len(s.RotationDeltas)
+	if rotations == 0 {
+		rotations = 1
+	}
+	if s.Rotation < 0 || s.Rotation >= rotations {
+		return fmt.Errorf("rotation %v of %v rotations", s.Rotation, rotations)
+	}
+	return checkRotations(s.block())
+}
+
+func unmarshal(x interface{}, data []byte) error {
+	if u, ok := x.(encoding.BinaryUnmarshaler); ok && data != nil {
+		return u.UnmarshalBinary(data)
+	}
+	return nil
+}
+
+func blockState(b Block) BlockState {
+	c := b.Copy()
+	return BlockState{
+		Shape:          c.Shape,
+		Points:         c.Points,
+		RotationDeltas: c.RotationDeltas,
+		Kicks:          c.Kicks,
+		Rotation:       c.rotation,
+	}
+}
+
+func (s BlockState) block() Block {
+	b := Block{
+		Shape:          s.Shape,
+		Points:         s.Points,
+		RotationDeltas: s.RotationDeltas,
+		Kicks:          s.Kicks,
+		rotation:       s.Rotation,
+	}
+	return b.Copy()
+}
+
+func blockStates(blocks []Block) []BlockState {
+	states := make([]BlockState, len(blocks))
+	for i, b := range blocks {
+		states[i] = blockState(b)
+	}
+	return states
+}
+
+func blocksFromStates(states []BlockState) []Block {
+	blocks := make([]Block, len(states))
+	for i, s := range states {
+		blocks[i] = s.block()
+	}
+	return blocks
+}
+
+func keyStates(keys []*repeatableKey) []KeyState {
+	states := make([]KeyState, len(keys))
+	for i, k := range keys {
+		states[i] = KeyState{k.timer, k.initialDelay, k.fastDelay, k.down}
+	}
+	return states
+}
+
+func keysFromStates(states []KeyState) []*repeatableKey {
+	keys := make([]*repeatableKey, len(states))
+	for i, s := range states {
+		keys[i] = &repeatableKey{s.Timer, s.InitialDelay, s.FastDelay, s.Down}
+	}
+	return keys
+}
+
+func copyBools(b []bool) []bool {
+	return append([]bool{}, b...)
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestRestoredGameContinuesLikeTheOriginal(t *testing.T) {
+	encodings := map[string]func(Snapshot) Snapshot{
+		"json":   jsonRoundTrip,
+		"binary": binaryRoundTrip,
+	}
+	for name, encode := range encodings {
+		original, originalScores := createSeededTwoPlayerGame(7)
+		for frame := 0; frame < 100; frame++ {
+			original.Update(scriptedInputs(frame)...)
+		}
+		s, err := original.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored, restoredScores := createSeededTwoPlayerGame(123)
+		if err := restored.Restore(encode(s)); err != nil {
+			t.Fatal(name, err)
+		}
+		for frame := 100; frame < 300; frame++ {
+			original.Update(scriptedInputs(frame)...)
+			restored.Update(scriptedInputs(frame)...)
+		}
+		if gameToString(original) != gameToString(restored) {
+			t.Error(name, "games differ", gameToString(original), gameToString(restored))
+		}
+		checkBlocksEqual(t, restored.PreviewBlocks(), original.PreviewBlocks()...)
+		for team := 0; team < 2; team++ {
+			checkInt(t, restoredScores.ScoreForTeam(team),
+				originalScores.ScoreForTeam(team), name+" score")
+		}
+	}
+}
+
+func jsonRoundTrip(s Snapshot) Snapshot {
+	data, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	var decoded Snapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		panic(err)
+	}
+	return decoded
+}
+
+func binaryRoundTrip(s Snapshot) Snapshot {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	var decoded Snapshot
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	return decoded
+}
+
+func TestRestoringSnapshotRollsGameBack(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.SetInitialLeftRightKeyDelay(1)
+	logic.StartNewGame(1)
+	logic.Update(InputEvent{0, RightPressed})
+	s, _ := logic.Snapshot()
+	logic.Update(InputEvent{0, HardDrop})
+	logic.Update()
+	checkGame(t, logic, "block dropped and new one moved right",
+		".0.",
+		"...",
+		".0.",
+	)
+	if err := logic.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	checkGame(t, logic, "rolled back",
+		".0.",
+		"...",
+		"...",
+	)
+	logic.Update()
+	logic.Update()
+	checkGame(t, logic, "right key is still repeated",
+		"..0",
+		"...",
+		"...",
+	)
+}
+
+func TestSnapshotIsNotChangedByGame(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	before := fmt.Sprint(s)
+	logic.Update(InputEvent{0, RightPressed})
+	logic.Board().SetAt(1, 1, 0)
+	if after := fmt.Sprint(s); after != before {
+		t.Error("snapshot changed from", before, "to", after)
+	}
+}
+
+func TestSnapshotKeepsHiddenRows(t *testing.T) {
+	original := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 3}})
+	original.SetHiddenRows(2)
+	original.StartNewGame(1)
+	original.Board().SetAt(1, 3, 0)
+	s, err := original.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := createSingleBlockGame(1, BoardSize{2, 2}, []Point{{0, 3}})
+	if err := restored.Restore(jsonRoundTrip(s)); err != nil {
+		t.Fatal(err)
+	}
+	checkInt(t, restored.Board().VisibleHeight(), 2, "visible height")
+	checkInt(t, restored.Board().At(1, 3), 0, "hidden field")
+}
+
+func TestSnapshotWithOtherVersionCanNotBeRestored(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	s.Version++
+	if err := logic.Restore(s); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestIncompleteSnapshotCanNotBeRestored(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	s.Players = 2
+	if err := logic.Restore(s); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestSnapshotWithInvalidRotationCanNotBeRestored(t *testing.T) {
+	logic := NewLogic(NewBlockFactory().CreateT)
+	logic.SetBoardSizeForPlayerCount(1, BoardSize{5, 5})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	s.Blocks[0].Rotation = 7
+	if err := logic.Restore(s); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestSnapshotWithUnevenBoardCanNotBeRestored(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	s.Board[1] = s.Board[1][:2]
+	if err := logic.Restore(s); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestFailedRestoreLeavesGameUnchanged(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{3, 3}, []Point{{0, 2}})
+	timer := NewLevelTimer(ClassicSpeedCurve, 10)
+	logic.SetDropTimer(timer)
+	logic.SetScorer(NewTeamScorer())
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	timer.SetStartLevel(3)
+	timer.Reset()
+	logic.Update(InputEvent{0, HardDrop})
+	s.Scorer = []byte("invalid")
+	if err := logic.Restore(s); err == nil {
+		t.Fatal("error expected")
+	}
+	checkInt(t, logic.Level(), 3, "level")
+	checkGame(t, logic, "game not restored",
+		"...",
+		"...",
+		"0..",
+	)
+}
+
+func TestSnapshotWithBlockOutsideBoardCanNotBeRestored(t *testing.T) {
+	logic := createSingleBlockGame(1, BoardSize{4, 3}, []Point{{0, 2}})
+	logic.StartNewGame(1)
+	s, _ := logic.Snapshot()
+	s.Blocks[0].Points[0].X = 10
+	if err := logic.Restore(s); err == nil {
+		t.Error("error expected")
+	}
+}
@@ -1,47 +1,109 @@
 package game
 
-type TeamScorer struct {
-	playerToTeam [4]int
-	teamScores   [4]int
-}
+import (
+	"bytes"
+	"encoding/gob"
+)
 
-var lineScores = [...]int{
-	0,
-	1, 3, 6, 10,
-	15, 21, 28, 36,
-	45, 55, 66, 78,
-	91, 105, 120, 136,
+// TeamScorer scores the lines removed by each team. Players that were not
+// assigned to a team play for team 0. Any number of players and teams is
+// supported.
+type TeamScorer struct {
+	playerToTeam []int
+	teamScores   []int
+	playerScores []int
+	softDrop     int
+	hardDrop     int
 }
 
 func NewTeamScorer() *TeamScorer {
 	return &TeamScorer{}
 }
 
+// lineScore is the score for removing the given number of lines at once. Each
+// additional line is worth one point more than the one before.
+func lineScore(lines int) int {
+	return lines * (lines + 1) / 2
+}
+
 func (s *TeamScorer) AssignPlayerToTeam(player, team int) {
+	s.playerToTeam = grow(s.playerToTeam, player)
 	s.playerToTeam[player] = team
 }
 
+// SetDropScores sets the points given for every field that a player moves the
+// block down by a soft drop and by a hard drop. The default is 0 for both.
+func (s *TeamScorer) SetDropScores(softDrop, hardDrop int) {
+	s.softDrop = softDrop
+	s.hardDrop = hardDrop
+}
+
+func (s *TeamScorer) teamOf(player int) int {
+	if player < len(s.playerToTeam) {
+		return s.playerToTeam[player]
+	}
+	return 0
+}
+
 func (s *TeamScorer) ScoreForTeam(team int) int {
-	return s.teamScores[team]
+	if team < len(s.teamScores) {
+		return s.teamScores[team]
+	}
+	return 0
+}
+
+// ScoreForPlayer is the player's contribution to the team score, i.e. the score
+// that the player's own removed lines would have made plus the player's drop
+// scores. Since lines removed together score more than separately, the
+// contributions of a team's players usually add up to less than the team's
+// score.
+func (s *TeamScorer) ScoreForPlayer(player int) int {
+	if player < len(s.playerScores) {
+		return s.playerScores[player]
+	}
+	return 0
 }
 
 func (s *TeamScorer) LinesRemoved(linesForPlayer [][]int) {
 	teamLines := s.assembleLinesForAllTeamsOfAllPlayers(linesForPlayer)
 	for team, lines := range teamLines {
-		lineCount := countDistinct(lines)
-		s.teamScores[team] += lineScores[lineCount]
+		s.teamScores = grow(s.teamScores, team)
+		s.teamScores[team] += lineScore(countDistinct(lines))
+	}
+	for player, lines := range linesForPlayer {
+		s.playerScores = grow(s.playerScores, player)
+		s.playerScores[player] += lineScore(countDistinct(lines))
 	}
 }
 
-func (s *TeamScorer) assembleLinesForAllTeamsOfAllPlayers(linesForPlayer [][]int) [4][]int {
-	var teamLines [4][]int
+func (s *TeamScorer) BlocksDropped(events []DropEvent) {
+	for _, e := range events {
+		score := e.SoftDropDistance*s.softDrop + e.HardDropDistance*s.hardDrop
+		team := s.teamOf(e.Player)
+		s.teamScores = grow(s.teamScores, team)
+		s.teamScores[team] += score
+		s.playerScores = grow(s.playerScores, e.Player)
+		s.playerScores[e.Player] += score
+	}
+}
+
+func (s *TeamScorer) assembleLinesForAllTeamsOfAllPlayers(linesForPlayer [][]int) map[int][]int {
+	teamLines := make(map[int][]int)
 	for player, lines := range linesForPlayer {
-		team := s.playerToTeam[player]
+		team := s.teamOf(player)
 		teamLines[team] = append(teamLines[team], lines...)
 	}
 	return teamLines
 }
 
+// grow makes sure that index is valid in s, appending zeros if necessary.
+func grow(s []int, index int) []int {
+	for len(s) <= index {
+		s = append(s, 0)
+	}
+	return s
+}
+
 func countDistinct(lines []int) int {
 	count := 0
 	for i, line := range lines {
@@ -52,17 +114,62 @@ func countDistinct(lines []int) int {
 	return count
 }
 
-func contains(lines []int, line int) bool {
-	for _, l := range lines {
-		if l == line {
+func contains(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
 			return true
 		}
 	}
 	return false
 }
 
+// PlayerAdded does nothing, new players play for team 0 until they are
+// assigned to another team.
+func (s *TeamScorer) PlayerAdded(player int) {}
+
+// PlayerRemoved forgets the player's team and contribution. The following
+// players move down by one, keeping their teams and contributions.
+func (s *TeamScorer) PlayerRemoved(player int) {
+	if player < len(s.playerToTeam) {
+		s.playerToTeam = withoutInt(s.playerToTeam, player)
+	}
+	if player < len(s.playerScores) {
+		s.playerScores = withoutInt(s.playerScores, player)
+	}
+}
+
 func (s *TeamScorer) Reset() {
 	for i := range s.teamScores {
 		s.teamScores[i] = 0
 	}
+	for i := range s.playerScores {
+		s.playerScores[i] = 0
+	}
+}
+
+type teamScorerState struct {
+	PlayerToTeam []int
+	TeamScores   []int
+	PlayerScores []int
+}
+
+func (s *TeamScorer) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(teamScorerState{
+		s.playerToTeam,
+		s.teamScores,
+		s.playerScores,
+	})
+	return buf.Bytes(), err
+}
+
+func (s *TeamScorer) UnmarshalBinary(data []byte) error {
+	var state teamScorerState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	s.playerToTeam = state.PlayerToTeam
+	s.teamScores = state.TeamScores
+	s.playerScores = state.PlayerScores
+	return nil
 }
@@ -27,8 +27,8 @@ func TestEachTeamIsScoredByItsRemovedLines(t *testing.T) {
 		{},
 	})
 
-	if score := s.ScoreForTeam(3); score != lineScores[2] {
-		t.Error("team 3 scored", score, "but expected", lineScores[2])
+	if score := s.ScoreForTeam(3); score != lineScore(2) {
+		t.Error("team 3 scored", score, "but expected", lineScore(2))
 	}
 }
 
@@ -37,7 +37,7 @@ func TestTeamScoresAddUpWithRemovedLines(t *testing.T) {
 	s.AssignPlayerToTeam(0, 0)
 	s.LinesRemoved([][]int{{1}})
 	s.LinesRemoved([][]int{{1, 2, 3}})
-	expected := lineScores[1] + lineScores[3]
+	expected := lineScore(1) + lineScore(3)
 	if score := s.ScoreForTeam(0); score != expected {
 		t.Errorf("expected %v but score was %v", expected, score)
 	}
@@ -51,8 +51,8 @@ func TestLinesForAllPlayersInATeamAreAddedForScore(t *testing.T) {
 		{1, 2},
 		{3},
 	})
-	if score := s.ScoreForTeam(0); score != lineScores[3] {
-		t.Errorf("expected %v but score was %v", lineScores[3], score)
+	if score := s.ScoreForTeam(0); score != lineScore(3) {
+		t.Errorf("expected %v but score was %v", lineScore(3), score)
 	}
 }
 
@@ -64,8 +64,8 @@ func TestTwoPlayers_OnSameTeam_RemovingSameLine_CountsOnlyOne(t *testing.T) {
 		{1, 2, 3},
 		{1, 3, 5},
 	})
-	if score := s.ScoreForTeam(0); score != lineScores[4] {
-		t.Errorf("expected %v but score was %v", lineScores[4], score)
+	if score := s.ScoreForTeam(0); score != lineScore(4) {
+		t.Errorf("expected %v but score was %v", lineScore(4), score)
 	}
 }
 
@@ -78,3 +78,103 @@ func TestResettingSetsAllScoresToZero(t *testing.T) {
 		t.Errorf("expected 0 but score was %v", score)
 	}
 }
+
+func TestScoresCanBeMarshaled(t *testing.T) {
+	s := NewTeamScorer()
+	s.AssignPlayerToTeam(1, 2)
+	s.LinesRemoved([][]int{{}, {1, 2}})
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewTeamScorer()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	restored.LinesRemoved([][]int{{}, {1}})
+	expected := lineScore(2) + lineScore(1)
+	if score := restored.ScoreForTeam(2); score != expected {
+		t.Errorf("expected %v but score was %v", expected, score)
+	}
+}
+
+func TestMoreThanFourPlayersAndTeamsAreSupported(t *testing.T) {
+	s := NewTeamScorer()
+	s.AssignPlayerToTeam(5, 7)
+	s.LinesRemoved([][]int{{}, {}, {}, {}, {}, {1, 2}})
+	if score := s.ScoreForTeam(7); score != lineScore(2) {
+		t.Errorf("expected %v but score was %v", lineScore(2), score)
+	}
+	if score := s.ScoreForTeam(8); score != 0 {
+		t.Errorf("unknown team should have score 0 but was %v", score)
+	}
+}
+
+func TestUnassignedPlayersPlayForTeamZero(t *testing.T) {
+	s := NewTeamScorer()
+	s.LinesRemoved([][]int{{}, {}, {1}})
+	if score := s.ScoreForTeam(0); score != lineScore(1) {
+		t.Errorf("expected %v but score was %v", lineScore(1), score)
+	}
+}
+
+func TestAnyNumberOfLinesCanBeScored(t *testing.T) {
+	lines := make([]int, 20)
+	for i := range lines {
+		lines[i] = i
+	}
+	s := NewTeamScorer()
+	s.LinesRemoved([][]int{lines})
+	if score := s.ScoreForTeam(0); score != 210 {
+		t.Errorf("expected 210 but score was %v", score)
+	}
+}
+
+func TestPlayerScoresAreTheirOwnContributions(t *testing.T) {
+	s := NewTeamScorer()
+	s.LinesRemoved([][]int{
+		{1, 2},
+		{2, 3, 4},
+	})
+	if score := s.ScoreForTeam(0); score != lineScore(4) {
+		t.Errorf("expected team score %v but was %v", lineScore(4), score)
+	}
+	if score := s.ScoreForPlayer(0); score != lineScore(2) {
+		t.Errorf("expected player 0 score %v but was %v", lineScore(2), score)
+	}
+	if score := s.ScoreForPlayer(1); score != lineScore(3) {
+		t.Errorf("expected player 1 score %v but was %v", lineScore(3), score)
+	}
+	s.Reset()
+	if score := s.ScoreForPlayer(1); score != 0 {
+		t.Errorf("expected 0 after reset but was %v", score)
+	}
+}
+
+func TestDropsScoreNothingByDefault(t *testing.T) {
+	s := NewTeamScorer()
+	s.BlocksDropped([]DropEvent{{Player: 0, SoftDropDistance: 3, HardDropDistance: 5}})
+	if score := s.ScoreForTeam(0); score != 0 {
+		t.Errorf("expected 0 but score was %v", score)
+	}
+}
+
+func TestDropsAreScoredForPlayerAndTeam(t *testing.T) {
+	s := NewTeamScorer()
+	s.SetDropScores(1, 2)
+	s.AssignPlayerToTeam(0, 1)
+	s.AssignPlayerToTeam(1, 1)
+	s.BlocksDropped([]DropEvent{
+		{Player: 0, SoftDropDistance: 3},
+		{Player: 1, SoftDropDistance: 1, HardDropDistance: 5},
+	})
+	if score := s.ScoreForTeam(1); score != 3+1+10 {
+		t.Errorf("expected team score %v but was %v", 3+1+10, score)
+	}
+	if score := s.ScoreForPlayer(0); score != 3 {
+		t.Errorf("expected player 0 score 3 but was %v", score)
+	}
+	if score := s.ScoreForPlayer(1); score != 11 {
+		t.Errorf("expected player 1 score 11 but was %v", score)
+	}
+}
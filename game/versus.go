@@ -0,0 +1,245 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Versus lets several games play against each other, each on its own board.
+// Lines removed on one board are sent as garbage rows to the board's target.
+// Garbage arrives after a delay and can be cancelled by removing lines before
+// it arrives.
+//
+// Players are numbered across all games: with two players per game, players 0
+// and 1 play in game 0, players 2 and 3 in game 1 and so on.
+type Versus struct {
+	games        []*Logic
+	players      int
+	targets      []int
+	attacks      AttackTable
+	garbageDelay int
+	pending      [][]garbage
+	seed         int64
+	holes        *rand.Rand
+}
+
+// garbage is a number of rows waiting to be inserted into a board in the given
+// number of frames.
+type garbage struct {
+	rows, delay int
+}
+
+// AttackTable maps the number of lines removed at once (the index) to the
+// number of garbage rows sent to the target.
+type AttackTable []int
+
+// GuidelineAttackTable sends nothing for a single line, one row for a double,
+// two for a triple and four for four lines.
+var GuidelineAttackTable = AttackTable{0, 0, 1, 2, 4}
+
+// Garbage returns the number of rows sent for removing the given number of
+// lines. For more lines than are in the table, each line adds one more row.
+func (t AttackTable) Garbage(lines int) int {
+	if len(t) == 0 {
+		return 0
+	}
+	if last := len(t) - 1; lines > last {
+		return t[last] + lines - last
+	}
+	return t[lines]
+}
+
+// NewVersus creates a versus game of the given games. Initially every game
+// targets the next one, the last one targets the first.
+func NewVersus(games ...*Logic) *Versus {
+	targets := make([]int, len(games))
+	for i := range targets {
+		targets[i] = (i + 1) % len(games)
+	}
+	return &Versus{
+		games:   games,
+		targets: targets,
+		attacks: GuidelineAttackTable,
+	}
+}
+
+func (v *Versus) SetAttackTable(t AttackTable) {
+	v.attacks = t
+}
+
+// SetGarbageDelay sets the number of frames before garbage is inserted into
+// the target's board.
+func (v *Versus) SetGarbageDelay(frames int) {
+	v.garbageDelay = frames
+}
+
+// SetSeed sets the seed for the next call to StartNewGame. It is passed on to
+// all games so they use the same sequence of blocks and it determines the
+// columns of the holes in the garbage rows.
+func (v *Versus) SetSeed(seed int64) {
+	v.seed = seed
+}
+
+// SetTarget makes the game send its garbage to the target game. If the target
+// is over, garbage goes to the next game that is still running.
+func (v *Versus) SetTarget(game, target int) {
+	v.targets[game] = target
+}
+
+func (v *Versus) Target(game int) int {
+	return v.targets[game]
+}
+
+func (v *Versus) Games() []*Logic {
+	return v.games
+}
+
+// StartNewGame starts all games with the given number of players each. All
+// games are validated before any of them is started.
+func (v *Versus) StartNewGame(playersPerGame int) error {
+	for i, g := range v.games {
+		if err := g.Validate(playersPerGame); err != nil {
+			return fmt.Errorf("game %v: %v", i, err)
+		}
+	}
+	v.players = playersPerGame
+	v.holes = rand.New(rand.NewSource(v.seed))
+	v.pending = make([][]garbage, len(v.games))
+	for i, g := range v.games {
+		g.SetSeed(v.seed)
+		if err := g.StartNewGame(playersPerGame); err != nil {
+			return fmt.Errorf("game %v: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// PendingGarbage returns the number of garbage rows that are waiting to be
+// inserted into the game's board.
+func (v *Versus) PendingGarbage(game int) int {
+	rows := 0
+	for _, g := range v.pending[game] {
+		rows += g.rows
+	}
+	return rows
+}
+
+func (v *Versus) Update(events ...InputEvent) {
+	for i, g := range v.games {
+		g.Update(v.eventsForGame(i, events)...)
+	}
+	for i, g := range v.games {
+		if lines := len(g.RemovedLines()); lines > 0 {
+			v.attack(i, v.attacks.Garbage(lines))
+		}
+	}
+	for i := range v.games {
+		v.receiveGarbage(i)
+	}
+}
+
+func (v *Versus) eventsForGame(game int, events []InputEvent) []InputEvent {
+	var gameEvents []InputEvent
+	for _, e := range events {
+		if e.Player/v.players == game {
+			gameEvents = append(gameEvents, InputEvent{e.Player % v.players, e.Command})
+		}
+	}
+	return gameEvents
+}
+
+// attack first cancels the game's own pending garbage and sends the rest to
+// its target.
+func (v *Versus) attack(game, rows int) {
+	pending := v.pending[game]
+	for rows > 0 && len(pending) > 0 {
+		if pending[0].rows > rows {
+			pending[0].rows -= rows
+			rows = 0
+		} else {
+			rows -= pending[0].rows
+			pending = pending[1:]
+		}
+	}
+	v.pending[game] = pending
+	if target := v.runningTarget(game); rows > 0 && target != -1 {
+		v.pending[target] = append(v.pending[target], garbage{rows, v.garbageDelay})
+	}
+}
+
+func (v *Versus) runningTarget(game int) int {
+	for i := 0; i < len(v.games); i++ {
+		target := (v.targets[game] + i) % len(v.games)
+		if target != game && !v.games[target].IsGameOver() {
+			return target
+		}
+	}
+	return -1
+}
+
+func (v *Versus) receiveGarbage(game int) {
+	g := v.games[game]
+	if g.IsGameOver() || g.IsPaused() {
+		return
+	}
+	pending := v.pending[game]
+	w, _ := g.Board().Size()
+	for len(pending) > 0 && pending[0].delay <= 0 && g.canReceiveGarbage() {
+		g.receiveGarbage(pending[0].rows, v.holes.Intn(w))
+		pending = pending[1:]
+	}
+	for i := range pending {
+		pending[i].delay--
+	}
+	v.pending[game] = pending
+}
+
+// canReceiveGarbage is true if the board can be pushed up without breaking the
+// full lines that are about to be removed.
+func (l *Logic) canReceiveGarbage() bool {
+	return !l.gameOver && len(l.fullLines) == 0 &&
+		(l.lineAnimation == nil || !l.lineAnimation.IsRunning())
+}
+
+// receiveGarbage inserts garbage rows at the bottom of the board and pushes the
+// players' blocks up out of it. The game ends if anything is pushed over the
+// top of the board, blaming the block that was pushed out, or NoPlayer if it
+// was the solid part of the board.
+func (l *Logic) receiveGarbage(rows, hole int) {
+	if l.physics.InsertGarbage(rows, []int{hole}) {
+		l.endGame(NoPlayer)
+		return
+	}
+	for b := range l.Blocks() {
+		if l.physics.isAboveTop(b) {
+			l.endGame(b)
+			return
+		}
+	}
+}
+
+// IsOver returns true if at most one game is still running.
+func (v *Versus) IsOver() bool {
+	running := 0
+	for _, g := range v.games {
+		if !g.IsGameOver() {
+			running++
+		}
+	}
+	return running <= 1
+}
+
+// Winner returns the game that is still running after all others are over, or
+// -1 if there is none.
+func (v *Versus) Winner() int {
+	winner := -1
+	for i, g := range v.games {
+		if !g.IsGameOver() {
+			if winner != -1 {
+				return -1
+			}
+			winner = i
+		}
+	}
+	return winner
+}
@@ -0,0 +1,132 @@
+package game
+
+import "testing"
+
+func TestAttackTableMapsLinesToGarbageRows(t *testing.T) {
+	table := AttackTable{0, 0, 1, 2, 4}
+	checkInt(t, table.Garbage(1), 0, "single")
+	checkInt(t, table.Garbage(2), 1, "double")
+	checkInt(t, table.Garbage(4), 4, "four lines")
+	checkInt(t, table.Garbage(6), 6, "more lines than in table")
+	checkInt(t, AttackTable{}.Garbage(3), 0, "empty table")
+}
+
+func TestRemovedLinesAreSentAsGarbageToTarget(t *testing.T) {
+	v := createVersus(2)
+	completeLineInGame(v, 0)
+	checkGame(t, v.Games()[0], "line removed",
+		"0.",
+		"..",
+		"..",
+		"..",
+	)
+	checkGarbageRows(t, v.Games()[1], 1)
+	checkGarbageRows(t, v.Games()[0], 0)
+}
+
+func TestPlayersAreMappedToTheirGames(t *testing.T) {
+	v := createVersus(2)
+	v.Update(InputEvent{1, HardDrop})
+	checkGame(t, v.Games()[1], "block of player 1 dropped",
+		"..",
+		"..",
+		"..",
+		"0.",
+	)
+	checkGame(t, v.Games()[0], "block of player 0 did not move",
+		"0.",
+		"..",
+		"..",
+		"..",
+	)
+}
+
+func TestGarbageArrivesAfterDelay(t *testing.T) {
+	v := createVersus(2)
+	v.SetGarbageDelay(2)
+	completeLineInGame(v, 0)
+	checkInt(t, v.PendingGarbage(1), 1, "pending garbage")
+	v.Update()
+	checkGarbageRows(t, v.Games()[1], 0)
+	v.Update()
+	checkGarbageRows(t, v.Games()[1], 1)
+	checkInt(t, v.PendingGarbage(1), 0, "pending garbage")
+}
+
+func TestRemovingLinesCancelsPendingGarbage(t *testing.T) {
+	v := createVersus(2)
+	v.SetGarbageDelay(10)
+	v.pending[1] = []garbage{{rows: 3, delay: 10}}
+	completeLineInGame(v, 1)
+	checkInt(t, v.PendingGarbage(1), 2, "pending garbage after cancelling")
+	checkInt(t, v.PendingGarbage(0), 0, "garbage sent back")
+}
+
+func TestGarbageGoesToNextRunningGameIfTargetIsOver(t *testing.T) {
+	v := createVersus(3)
+	v.SetTarget(0, 2)
+	checkInt(t, v.Target(0), 2, "target")
+	v.Games()[2].endGame(0)
+	completeLineInGame(v, 0)
+	checkGarbageRows(t, v.Games()[0], 0)
+	checkGarbageRows(t, v.Games()[1], 1)
+	checkGarbageRows(t, v.Games()[2], 0)
+}
+
+func TestGarbagePushingBoardOverTopEndsGame(t *testing.T) {
+	v := createVersus(2)
+	spy := &spyGameOverObserver{}
+	v.Games()[1].AddGameOverObserver(spy)
+	v.Games()[1].Board().SetAt(1, 3, 0)
+	if v.IsOver() {
+		t.Fatal("versus over before the start")
+	}
+	completeLineInGame(v, 0)
+	if !v.Games()[1].IsGameOver() {
+		t.Fatal("game 1 not over")
+	}
+	checkIntsEqual(t, spy.players, []int{NoPlayer}, "game over players")
+	if !v.IsOver() {
+		t.Error("versus not over")
+	}
+	checkInt(t, v.Winner(), 0, "winner")
+}
+
+func createVersus(games int) *Versus {
+	logics := make([]*Logic, games)
+	for i := range logics {
+		logics[i] = createSingleBlockGame(1, BoardSize{2, 4}, []Point{{0, 3}})
+	}
+	v := NewVersus(logics...)
+	v.SetAttackTable(AttackTable{0, 1})
+	v.StartNewGame(1)
+	return v
+}
+
+func completeLineInGame(v *Versus, game int) {
+	v.Games()[game].Board().SetAt(1, 0, 0)
+	v.Update(InputEvent{game, HardDrop})
+	v.Update()
+}
+
+func checkGarbageRows(t *testing.T, l *Logic, rows int) {
+	w, h := l.Board().Size()
+	count := 0
+	for y := 0; y < h; y++ {
+		garbage, holes := 0, 0
+		for x := 0; x < w; x++ {
+			switch l.Board().At(x, y) {
+			case Garbage:
+				garbage++
+			case NoPlayer:
+				holes++
+			}
+		}
+		if garbage == w-1 && holes == 1 {
+			count++
+		}
+	}
+	if count != rows {
+		t.Error("expected", rows, "garbage rows but have", count)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/sdl_mixer"
 	"github.com/veandco/go-sdl2/sdl_ttf"
-	"math/rand"
 	"os"
 	"strconv"
 	"time"
@@ -17,6 +16,10 @@ var renderer *sdl.Renderer
 
 var playerCount int
 
+// controllerPlayers maps the controllers to the players they control. Players
+// are renumbered when one of them leaves the game.
+var controllerPlayers = make(map[int]int)
+
 const blockSize = 20
 
 func RunGame() {
@@ -29,8 +32,11 @@ func RunGame() {
 			playerCount = int(count)
 		}
 	}
+	for player := 0; player < playerCount; player++ {
+		controllerPlayers[player] = player
+	}
 	var err error
-	window, renderer, err = sdl.CreateWindowAndRenderer(800, 600, 0)
+	window, renderer, err = sdl.CreateWindowAndRenderer(1000, 600, 0)
 	if err != nil {
 		panic(err)
 	}
@@ -42,7 +48,8 @@ func RunGame() {
 	initControllers()
 	initAssets()
 	defer closeAssets()
-	g := game.NewLogic(randomBlock)
+	factory := game.NewBlockFactoryWithKicks(game.SuperRotationSystem)
+	g := game.NewSeededLogic(factory.SevenBagRandomizer)
 	g.SetBoardSizeForPlayerCount(1, game.BoardSize{10, 18})
 	g.SetBlockStartPositions(1, []game.Point{{5, 16}})
 	g.SetBoardSizeForPlayerCount(2, game.BoardSize{10, 18})
@@ -51,7 +58,15 @@ func RunGame() {
 	g.SetBlockStartPositions(3, []game.Point{{6, 16}, {2, 16}, {10, 16}})
 	g.SetBoardSizeForPlayerCount(4, game.BoardSize{16, 18})
 	g.SetBlockStartPositions(4, []game.Point{{10, 16}, {2, 16}, {14, 16}, {6, 16}})
-	g.SetDropTimer(&dropTimer{})
+	for players := 5; players <= 8; players++ {
+		g.SetBoardSizeForPlayerCount(players, game.BoardSize{players * 4, 18})
+	}
+	g.SetDropTimer(game.NewLevelTimer(
+		game.TableSpeedCurve(27, 24, 21, 18, 15, 12, 10, 8, 6, 5, 4, 3, 2), 10))
+	g.SetHiddenRows(2)
+	g.SetLockDelay(30)
+	g.SetLockDelayResetOnMove(true)
+	g.SetMaxLockDelayResets(15)
 	g.SetLineAnimation(animation)
 	g.SetInitialLeftRightKeyDelay(9)
 	g.SetShortLeftRightKeyDelay(2)
@@ -59,7 +74,10 @@ func RunGame() {
 	g.SetShortDownKeyDelay(1)
 	g.SetScorer(scorer)
 	g.SetSoundPlayer(game.NewSoundPlayer(sounds))
-	g.StartNewGame(playerCount)
+	g.AddGameOverObserver(results{})
+	recorder = game.NewRecorder(g)
+	defer saveReplay()
+	startNewGame(g)
 	animation.board = g.Board()
 
 	running := true
@@ -84,7 +102,7 @@ func RunGame() {
 				case sdl.K_ESCAPE:
 					running = false
 				case sdl.K_n:
-					g.StartNewGame(playerCount)
+					startNewGame(g)
 					scorer.Reset()
 				}
 
@@ -93,7 +111,17 @@ func RunGame() {
 					inputs = append(inputs, input)
 				}
 			case *sdl.ControllerButtonEvent:
-				player := int(event.Which)
+				controller := int(event.Which)
+				if event.Button == sdl.CONTROLLER_BUTTON_BACK {
+					if event.State == sdl.PRESSED {
+						joinOrLeave(g, controller)
+					}
+					break
+				}
+				player, playing := controllerPlayers[controller]
+				if !playing {
+					break
+				}
 				switch event.Button {
 				case sdl.CONTROLLER_BUTTON_A:
 					if event.State == sdl.PRESSED {
@@ -115,6 +143,18 @@ func RunGame() {
 						state = game.RightReleased
 					}
 					inputs = append(inputs, game.InputEvent{player, state})
+				case sdl.CONTROLLER_BUTTON_X:
+					if event.State == sdl.PRESSED {
+						inputs = append(inputs, game.InputEvent{player, game.Hold})
+					}
+				case sdl.CONTROLLER_BUTTON_START:
+					if event.State == sdl.PRESSED {
+						inputs = append(inputs, game.InputEvent{player, game.Pause})
+					}
+				case sdl.CONTROLLER_BUTTON_DPAD_UP:
+					if event.State == sdl.PRESSED {
+						inputs = append(inputs, game.InputEvent{player, game.HardDrop})
+					}
 				case sdl.CONTROLLER_BUTTON_DPAD_DOWN:
 					state := game.DownPressed
 					if event.State == sdl.RELEASED {
@@ -124,17 +164,49 @@ func RunGame() {
 				}
 			}
 		}
-		update(g, &inputs)
+		update(&inputs)
+		showLevel(g.Level())
 		sdl.Delay(30)
 		draw(g)
 	}
 }
 
-func update(g *game.Logic, inputs *[]game.InputEvent) {
-	g.Update((*inputs)...)
+var recorder *game.Recorder
+
+func startNewGame(g *game.Logic) {
+	g.SetSeed(time.Now().UnixNano())
+	fmt.Println("starting new game with seed", g.Seed())
+	if err := recorder.StartNewGame(playerCount); err != nil {
+		panic(err)
+	}
+}
+
+func update(inputs *[]game.InputEvent) {
+	recorder.Update((*inputs)...)
 	*inputs = make([]game.InputEvent, 0)
 }
 
+var shownLevel = -1
+
+func showLevel(level int) {
+	if level != shownLevel {
+		window.SetTitle("Multiblocks - Level " + strconv.Itoa(level))
+		shownLevel = level
+	}
+}
+
+func saveReplay() {
+	file, err := os.Create("last_game.replay")
+	if err != nil {
+		fmt.Println("unable to save replay:", err)
+		return
+	}
+	defer file.Close()
+	if err := recorder.Replay().Write(file); err != nil {
+		fmt.Println("unable to save replay:", err)
+	}
+}
+
 func initControllers() {
 	joystickCount := sdl.NumJoysticks()
 	fmt.Println(joystickCount, "joysticks detected")
@@ -154,11 +226,9 @@ func initControllers() {
 func initAssets() {
 	initKeys()
 	initColors()
-	initFactory()
 	initScorer()
 	initSounds()
 	animation = &lineAnimation{}
-	rand.Seed(time.Now().UnixNano())
 }
 
 var keyDownMap map[sdl.Keycode]game.InputEvent
@@ -172,10 +242,15 @@ func initKeys() {
 		sdl.K_UP:    game.InputEvent{0, game.RotateRight},
 		sdl.K_y:     game.InputEvent{0, game.RotateLeft},
 		sdl.K_x:     game.InputEvent{0, game.RotateRight},
+		sdl.K_SPACE: game.InputEvent{0, game.HardDrop},
+		sdl.K_p:     game.InputEvent{0, game.Pause},
+		sdl.K_c:     game.InputEvent{0, game.Hold},
 		sdl.K_a:     game.InputEvent{1, game.LeftPressed},
 		sdl.K_d:     game.InputEvent{1, game.RightPressed},
 		sdl.K_s:     game.InputEvent{1, game.DownPressed},
 		sdl.K_w:     game.InputEvent{1, game.RotateRight},
+		sdl.K_q:     game.InputEvent{1, game.HardDrop},
+		sdl.K_e:     game.InputEvent{1, game.Hold},
 		sdl.K_KP_4:  game.InputEvent{2, game.LeftPressed},
 		sdl.K_KP_6:  game.InputEvent{2, game.RightPressed},
 		sdl.K_KP_5:  game.InputEvent{2, game.DownPressed},
@@ -208,6 +283,10 @@ func initColors() {
 		[]color{{0, 192, 0}, {0, 167, 0}},
 		[]color{{64, 64, 255}, {54, 54, 235}},
 		[]color{{235, 0, 235}, {205, 0, 205}},
+		[]color{{255, 160, 0}, {225, 135, 0}},
+		[]color{{0, 210, 210}, {0, 180, 180}},
+		[]color{{240, 240, 0}, {210, 210, 0}},
+		[]color{{160, 96, 32}, {135, 80, 25}},
 	}
 }
 
@@ -215,25 +294,6 @@ type color [3]uint8
 
 var colors [][]color
 
-func initFactory() {
-	factory := game.NewBlockFactory()
-	blockNewers = []func() game.Block{
-		factory.CreateO,
-		factory.CreateT,
-		factory.CreateI,
-		factory.CreateL,
-		factory.CreateJ,
-		factory.CreateS,
-		factory.CreateZ,
-	}
-}
-
-var blockNewers []func() game.Block
-
-func randomBlock() game.Block {
-	return blockNewers[rand.Int()%len(blockNewers)]()
-}
-
 func initScorer() {
 	scorer = game.NewTeamScorer()
 	for i := 0; i < playerCount; i++ {
@@ -244,8 +304,8 @@ func initScorer() {
 var scorer *game.TeamScorer
 
 func drawScore() {
-	_, boardH := animation.board.Size()
-	for t := 0; t < 4; t++ {
+	boardH := animation.board.VisibleHeight()
+	for t := 0; t < playerCount; t++ {
 		s := scorer.ScoreForTeam(t)
 		y := (t + 1 + boardH) * blockSize
 		c := dark(t)
@@ -264,7 +324,9 @@ func draw(g *game.Logic) {
 	board := g.Board()
 	blocks := g.Blocks()
 	previews := g.PreviewBlocks()
-	w, h := board.Size()
+	held := g.HeldBlocks()
+	w, _ := board.Size()
+	h := board.VisibleHeight()
 	for x := 0; x < w; x++ {
 		for y := 0; y < h; y++ {
 			c := light(board.At(x, y))
@@ -272,6 +334,12 @@ func draw(g *game.Logic) {
 		}
 	}
 
+	for player, b := range g.GhostBlocks() {
+		for _, p := range b.Points {
+			drawPiece(int32(p.X), int32(h-1-p.Y), backGroundColor, dark(player))
+		}
+	}
+
 	for player, b := range blocks {
 		for _, p := range b.Points {
 			drawPiece(int32(p.X), int32(h-1-p.Y), light(player), dark(player))
@@ -284,18 +352,80 @@ func draw(g *game.Logic) {
 		}
 	}
 
+	for player, b := range held {
+		for _, p := range b.Points {
+			drawPiece(int32(w+7+p.X), int32(h-1-player*5-p.Y), light(player), dark(player))
+		}
+	}
+
 	animation.draw()
 	drawScore()
+	if g.IsGameOver() {
+		drawGameOver(w, h)
+	}
 
 	renderer.Present()
 }
 
+// joinOrLeave lets a controller's player join the running game or leave it.
+func joinOrLeave(g *game.Logic, controller int) {
+	if player, playing := controllerPlayers[controller]; playing {
+		if err := recorder.RemovePlayer(player); err != nil {
+			fmt.Println("player can not leave:", err)
+			return
+		}
+		delete(controllerPlayers, controller)
+		for c, p := range controllerPlayers {
+			if p > player {
+				controllerPlayers[c] = p - 1
+			}
+		}
+		playerCount--
+	} else {
+		p, err := recorder.AddPlayer()
+		if err != nil {
+			fmt.Println("player can not join:", err)
+			return
+		}
+		scorer.AssignPlayerToTeam(p, p)
+		controllerPlayers[controller] = p
+		playerCount++
+	}
+	animation.board = g.Board()
+}
+
+func drawGameOver(w, h int) {
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	renderer.SetDrawColor(0, 0, 0, 192)
+	renderer.FillRect(&sdl.Rect{0, 0, int32(w * blockSize), int32(h * blockSize)})
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+}
+
+type results struct{}
+
+func (results) GameOver(player int) {
+	if player == game.NoPlayer {
+		fmt.Println("game over, garbage pushed the board over the top")
+	} else {
+		fmt.Println("game over, player", player, "could not place a new block")
+	}
+	for t := 0; t < playerCount; t++ {
+		fmt.Println("team", t, "scored", scorer.ScoreForTeam(t))
+	}
+	fmt.Println("press N to start a new game")
+}
+
 var backGroundColor color = color{64, 64, 64}
 
+var garbageColor color = color{128, 128, 128}
+
 func light(player int) color {
 	if player == game.NoPlayer {
 		return backGroundColor
 	}
+	if player == game.Garbage {
+		return garbageColor
+	}
 	return colors[player][0]
 }
 
@@ -303,6 +433,9 @@ func dark(player int) color {
 	if player == game.NoPlayer {
 		return backGroundColor
 	}
+	if player == game.Garbage {
+		return garbageColor
+	}
 	return colors[player][1]
 }
 
@@ -315,25 +448,6 @@ func drawPiece(x, y int32, light, dark color) {
 	renderer.FillRect(r)
 }
 
-type dropTimer struct {
-	timer int
-}
-
-func (t *dropTimer) IsTimeToDrop() bool {
-	return t.timer == 0
-}
-
-func (t *dropTimer) Reset() {
-	t.timer = 27
-}
-
-func (t *dropTimer) Update() {
-	t.timer--
-	if t.timer < 0 {
-		t.Reset()
-	}
-}
-
 type lineAnimation struct {
 	board    game.Board
 	lines    []int
@@ -363,7 +477,8 @@ func (a *lineAnimation) Update() {
 
 func (a *lineAnimation) draw() {
 	if a.IsRunning() {
-		w, h := a.board.Size()
+		w, _ := a.board.Size()
+		h := a.board.VisibleHeight()
 		if a.blinking {
 			for _, line := range a.lines {
 				for x := 0; x < w; x++ {